@@ -0,0 +1,138 @@
+package sri
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
+)
+
+// A Severity describes how serious a Finding is.
+type Severity int
+
+const (
+	// SeverityInfo indicates something that is harmless but could be tidied up.
+	SeverityInfo Severity = iota
+	// SeverityWarning indicates something that weakens the integrity check.
+	SeverityWarning
+)
+
+// String implements the fmt.Stringer interface.
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "info"
+}
+
+// A Finding is a single issue found by Analyze.
+type Finding struct {
+	Severity Severity
+	// Algorithm is the algorithm of the offending entry.
+	Algorithm string
+	// Value is the digest of the offending entry, as it appeared in the SRI string.
+	Value string
+	// Message describes the issue.
+	Message string
+}
+
+// String implements the fmt.Stringer interface.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s-%s: %s", f.Severity, f.Algorithm, f.Value, f.Message)
+}
+
+// An Analysis is the result of Analyze.
+type Analysis struct {
+	// Findings describes each issue found, in the order they were found.
+	Findings []Finding
+}
+
+// Warnings returns just the findings that are of SeverityWarning.
+func (a *Analysis) Warnings() []Finding {
+	var ret []Finding
+	for _, f := range a.Findings {
+		if f.Severity == SeverityWarning {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+// weakAlgorithms are algorithms that are known to be broken and shouldn't be relied upon.
+var weakAlgorithms = map[string]bool{
+	"md5":    true,
+	AlgoSHA1: true,
+}
+
+// analysisHashes returns the set of hashes that Analyze understands; it's broader than NewChecker's
+// so that it can report on entries that would otherwise be rejected outright.
+func analysisHashes() map[string]HashFunc {
+	hashes := defaultHashes()
+	hashes["md5"] = md5.New
+	hashes[AlgoSHA1] = sha1.New
+	hashes[AlgoSHA512_256] = sha512.New512_256
+	hashes[AlgoSHA512_224] = sha512.New512_224
+	hashes[AlgoSHA3256] = sha3.New256
+	hashes[AlgoSHA3384] = sha3.New384
+	hashes[AlgoSHA3512] = sha3.New512
+	hashes[AlgoBLAKE2b256] = unkeyed(blake2b.New256)
+	hashes[AlgoBLAKE2b384] = unkeyed(blake2b.New384)
+	hashes[AlgoBLAKE2b512] = unkeyed(blake2b.New512)
+	hashes[AlgoBLAKE2s256] = unkeyed(blake2s.New256)
+	return hashes
+}
+
+// Analyze examines the given SRI string for issues that don't make it invalid but that may be worth
+// fixing: weak algorithms (sha1 and md5), algorithms that aren't part of the SRI spec, digests that
+// are listed more than once, entries for weaker algorithms that are redundant because a stronger one
+// is present (only considering the algorithms in the SRI spec and sha1), and digests that aren't in canonical (padded standard) base64.
+//
+// Besides the default set, it understands md5, sha1 and the other algorithms this package has
+// constructors for. It returns an error if the string is invalid.
+func Analyze(sri string) (*Analysis, error) {
+	c := newChecker(analysisHashes())
+	if err := c.parse(sri); err != nil {
+		return nil, err
+	}
+	a := &Analysis{}
+	add := func(severity Severity, name, value, msg string, args ...interface{}) {
+		a.Findings = append(a.Findings, Finding{Severity: severity, Algorithm: name, Value: value, Message: fmt.Sprintf(msg, args...)})
+	}
+	seen := map[string][][]byte{}
+	for _, field := range strings.FieldsFunc(sri, isSeparator) {
+		// We know all of these are valid since parse succeeded.
+		name, value, _ := splitField(field, c.supported)
+		value, _ = splitOptions(value)
+		decoded, _ := c.validateHash(c.hashSize(name, c.supported[name]), name, value)
+		if containsExact(seen[name], decoded) {
+			add(SeverityInfo, name, value, "digest is listed more than once")
+			continue
+		}
+		seen[name] = append(seen[name], decoded)
+		if canonical := base64.StdEncoding.EncodeToString(decoded); value != canonical {
+			add(SeverityInfo, name, value, "digest is not in canonical form; should be %s", canonical)
+		}
+	}
+	algorithms := c.Algorithms()
+	for i, name := range algorithms {
+		for _, value := range c.Expected(name) {
+			if weakAlgorithms[name] {
+				add(SeverityWarning, name, value, "%s is a weak algorithm and should not be relied upon", name)
+			} else if !recommendedAlgorithms[name] {
+				add(SeverityInfo, name, value, "%s is not part of the SRI spec so may not be understood by all clients", name)
+			}
+			// Only ranked algorithms can be compared; e.g. there's no basis for saying that sha256 is
+			// stronger than sha3-512 just because the latter isn't in the SRI spec.
+			if i > 0 && c.ranked(name) && c.ranked(algorithms[0]) {
+				add(SeverityInfo, name, value, "entry could be dropped since the stronger %s is present", algorithms[0])
+			}
+		}
+	}
+	return a, nil
+}
@@ -0,0 +1,78 @@
+package sri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyzeClean(t *testing.T) {
+	a, err := Analyze("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(a.Findings))
+}
+
+func TestAnalyze(t *testing.T) {
+	a, err := Analyze("sha384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=")
+	assert.NoError(t, err)
+	assert.Equal(t, []Finding{
+		{
+			Severity:  SeverityInfo,
+			Algorithm: "sha384",
+			Value:     "4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+			Message:   "digest is not in canonical form; should be 4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+		},
+		{
+			Severity:  SeverityInfo,
+			Algorithm: "sha1",
+			Value:     "plyJ8jPttaMEVHl2WQbzDVT4pfU=",
+			Message:   "digest is listed more than once",
+		},
+		{
+			Severity:  SeverityWarning,
+			Algorithm: "sha1",
+			Value:     "plyJ8jPttaMEVHl2WQbzDVT4pfU=",
+			Message:   "sha1 is a weak algorithm and should not be relied upon",
+		},
+		{
+			Severity:  SeverityInfo,
+			Algorithm: "sha1",
+			Value:     "plyJ8jPttaMEVHl2WQbzDVT4pfU=",
+			Message:   "entry could be dropped since the stronger sha384 is present",
+		},
+	}, a.Findings)
+	assert.Equal(t, 1, len(a.Warnings()))
+	assert.Equal(t, "warning: sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=: sha1 is a weak algorithm and should not be relied upon", a.Warnings()[0].String())
+}
+
+func TestAnalyzeNonStandard(t *testing.T) {
+	a, err := Analyze("md5-IdZNPlbFer1sm3bEsO3Mpw== sha3-256-m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8=")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(a.Warnings()))
+	assert.Equal(t, "md5", a.Warnings()[0].Algorithm)
+	assert.Equal(t, 2, len(a.Findings))
+	assert.Contains(t, a.Findings[1].Message, "not part of the SRI spec")
+}
+
+func TestAnalyzeUnrankedNotRedundant(t *testing.T) {
+	// sha3-512 and blake2b-256 aren't ranked, so there's no basis to say sha256 makes them redundant.
+	a, err := Analyze("sha3-512-v4v9Yv+vWmq3GVIn2MjvQ1plBnwf7e/1ZVc8b591dpV6sC1BWpLs9JAYtQwIdywFtsljpVGeh+HZEw6n82pHuA== blake2b-256-Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs= sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	for _, f := range a.Findings {
+		assert.NotContains(t, f.Message, "could be dropped", f.String())
+	}
+	// Redundancy between ranked algorithms is still reported.
+	a, err = Analyze("sha3-512-v4v9Yv+vWmq3GVIn2MjvQ1plBnwf7e/1ZVc8b591dpV6sC1BWpLs9JAYtQwIdywFtsljpVGeh+HZEw6n82pHuA== sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Contains(t, a.Findings, Finding{
+		Severity:  SeverityInfo,
+		Algorithm: "sha256",
+		Value:     "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		Message:   "entry could be dropped since the stronger sha384 is present",
+	})
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	_, err := Analyze("sha256-wibble")
+	assert.Error(t, err)
+}
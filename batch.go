@@ -0,0 +1,58 @@
+package sri
+
+import (
+	"fmt"
+	"io"
+)
+
+// A BatchChecker verifies a set of independent resources, each identified by an arbitrary string
+// (for example a file path) and each with its own SRI string.
+//
+// Like Checker, it is not safe for concurrent use, although writers for different resources can be
+// used concurrently as long as no new ones are being created.
+type BatchChecker struct {
+	checkers map[string]*Checker
+	// requested records the resources that Writer has been called for.
+	requested map[string]bool
+}
+
+// NewBatchChecker creates a new BatchChecker from the given map of resource identifiers to SRI strings.
+// It supports the same set of algorithms as NewChecker, and returns an error if any of the SRI strings are invalid.
+func NewBatchChecker(sris map[string]string) (*BatchChecker, error) {
+	checkers := make(map[string]*Checker, len(sris))
+	for id, sri := range sris {
+		c, err := NewChecker(sri)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid subresource integrity for %s: %w", id, err)
+		}
+		checkers[id] = c
+	}
+	return &BatchChecker{checkers: checkers, requested: make(map[string]bool, len(sris))}, nil
+}
+
+// Writer returns a writer that the content for the given resource should be written to.
+// It returns an error if that resource isn't known to this BatchChecker.
+func (b *BatchChecker) Writer(id string) (io.Writer, error) {
+	c, present := b.checkers[id]
+	if !present {
+		return nil, fmt.Errorf("Unknown resource %s", id)
+	}
+	b.requested[id] = true
+	return c, nil
+}
+
+// VerifyAll checks every resource and returns a map of the identifiers of those that failed to the
+// reason why; it is empty if they all succeeded. Resources that Writer was never called for fail
+// with ErrNoData, others with the same errors that Checker.Check returns (so an empty resource can
+// still succeed if its SRI string is for empty content).
+func (b *BatchChecker) VerifyAll() map[string]error {
+	failures := map[string]error{}
+	for id, c := range b.checkers {
+		if !b.requested[id] {
+			failures[id] = ErrNoData
+		} else if err := c.Check(); err != nil {
+			failures[id] = err
+		}
+	}
+	return failures
+}
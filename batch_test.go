@@ -0,0 +1,68 @@
+package sri
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchChecker(t *testing.T) {
+	b, err := NewBatchChecker(map[string]string{
+		"sandwich": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"burrito":  "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"nothing":  "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+	})
+	assert.NoError(t, err)
+	w, err := b.Writer("sandwich")
+	assert.NoError(t, err)
+	w.Write([]byte("I want a sandwich"))
+	w, err = b.Writer("burrito")
+	assert.NoError(t, err)
+	w.Write([]byte("I want a burrito"))
+	_, err = b.Writer("pizza")
+	assert.Error(t, err)
+
+	failures := b.VerifyAll()
+	assert.Equal(t, 2, len(failures))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(failures["burrito"], &ierr))
+	assert.Equal(t, ErrNoData, failures["nothing"])
+}
+
+func TestBatchCheckerAllSucceed(t *testing.T) {
+	b, err := NewBatchChecker(map[string]string{
+		"sandwich": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	})
+	assert.NoError(t, err)
+	w, err := b.Writer("sandwich")
+	assert.NoError(t, err)
+	w.Write([]byte("I want a sandwich"))
+	assert.Equal(t, 0, len(b.VerifyAll()))
+}
+
+func TestBatchCheckerEmpty(t *testing.T) {
+	b, err := NewBatchChecker(map[string]string{
+		"empty":    "sha256-47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=",
+		"sandwich": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	})
+	assert.NoError(t, err)
+	w, err := b.Writer("empty")
+	assert.NoError(t, err)
+	w.Write(nil)
+	_, err = b.Writer("sandwich")
+	assert.NoError(t, err)
+
+	failures := b.VerifyAll()
+	assert.Equal(t, 1, len(failures))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(failures["sandwich"], &ierr))
+}
+
+func TestBatchCheckerInvalid(t *testing.T) {
+	_, err := NewBatchChecker(map[string]string{
+		"sandwich": "wibble",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "sandwich")
+}
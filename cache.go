@@ -0,0 +1,79 @@
+package sri
+
+import (
+	"container/list"
+	"sync"
+)
+
+// A Cache creates Checkers from SRI strings, remembering the parsed form of recently used strings
+// so they don't need to be parsed again. It is safe for concurrent use.
+//
+// It supports the same set of algorithms as NewChecker.
+type Cache struct {
+	mutex   sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+// A cacheEntry is a single entry in the Cache's list.
+type cacheEntry struct {
+	sri       string
+	integrity *Integrity
+}
+
+// NewCache returns a new Cache that holds at most the given number of parsed SRI strings,
+// evicting the least recently used when it is full. If size is zero or negative the cache is unbounded.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		entries: map[string]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// Checker returns a new Checker for the given SRI string, equivalent to calling NewChecker on it.
+// Errors are not cached, so an invalid string is re-parsed every time it is requested.
+func (c *Cache) Checker(sri string) (*Checker, error) {
+	i, err := c.integrity(sri)
+	if err != nil {
+		return nil, err
+	}
+	return i.NewChecker(), nil
+}
+
+// Len returns the number of SRI strings currently held in the cache.
+func (c *Cache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.lru.Len()
+}
+
+// integrity returns the Integrity for the given SRI string, parsing it if it isn't already cached.
+func (c *Cache) integrity(sri string) (*Integrity, error) {
+	c.mutex.Lock()
+	if elem, present := c.entries[sri]; present {
+		c.lru.MoveToFront(elem)
+		c.mutex.Unlock()
+		return elem.Value.(*cacheEntry).integrity, nil
+	}
+	c.mutex.Unlock()
+	// Parse without holding the lock; at worst two goroutines parse the same string concurrently.
+	i, err := Parse(sri)
+	if err != nil {
+		return nil, err
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if elem, present := c.entries[sri]; present {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*cacheEntry).integrity, nil
+	}
+	c.entries[sri] = c.lru.PushFront(&cacheEntry{sri: sri, integrity: i})
+	if c.size > 0 && c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).sri)
+	}
+	return i, nil
+}
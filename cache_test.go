@@ -0,0 +1,65 @@
+package sri
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	cache := NewCache(10)
+	c1, err := cache.Checker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c1.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c1.Check())
+	// The second Checker should be fresh, not share any state with the first.
+	c2, err := cache.Checker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, c2.BytesWritten())
+	c2.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c2.Check())
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestCacheError(t *testing.T) {
+	cache := NewCache(10)
+	_, err := cache.Checker("sha256-wibble")
+	assert.Error(t, err)
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCacheEviction(t *testing.T) {
+	const (
+		sha256 = "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="
+		sha384 = "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"
+		sha512 = "sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="
+	)
+	cache := NewCache(2)
+	for _, sri := range []string{sha256, sha384, sha256, sha512} {
+		_, err := cache.Checker(sri)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.Len())
+	// sha384 was least recently used so should have been evicted.
+	assert.Contains(t, cache.entries, sha256)
+	assert.Contains(t, cache.entries, sha512)
+	assert.NotContains(t, cache.entries, sha384)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := NewCache(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := cache.Checker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+			assert.NoError(t, err)
+			c.Write([]byte("I want a sandwich"))
+			assert.NoError(t, c.Check())
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 1, cache.Len())
+}
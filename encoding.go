@@ -0,0 +1,44 @@
+package sri
+
+import (
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+// An Encoder encodes digests as strings, for use with Checker.ExpectedEncoded.
+// The encodings from encoding/base64 and encoding/base32 (e.g. base32.StdEncoding) all implement it.
+type Encoder interface {
+	EncodeToString(src []byte) string
+}
+
+// HexEncoding is an Encoder that encodes digests as lowercase hex.
+var HexEncoding Encoder = encoderFunc(hex.EncodeToString)
+
+// MultibaseBase32 is an Encoder that produces multibase strings (as used by IPFS and other
+// content-addressable systems) using lowercase, unpadded base32; that is, a 'b' prefix followed
+// by the RFC 4648 base32 encoding.
+var MultibaseBase32 Encoder = encoderFunc(func(src []byte) string {
+	return "b" + strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(src))
+})
+
+// An encoderFunc adapts a function to an Encoder.
+type encoderFunc func([]byte) string
+
+// EncodeToString implements the Encoder interface.
+func (f encoderFunc) EncodeToString(src []byte) string {
+	return f(src)
+}
+
+// ExpectedEncoded is like Expected but returns the expected hashes encoded with the given Encoder.
+func (c *Checker) ExpectedEncoded(name string, enc Encoder) []string {
+	expected, present := c.expected[strings.ToLower(name)]
+	if !present {
+		return nil
+	}
+	ret := make([]string, len(expected))
+	for i, e := range expected {
+		ret[i] = enc.EncodeToString(e)
+	}
+	return ret
+}
@@ -0,0 +1,19 @@
+package sri
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedEncoded(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ZNN7PVGZFUXLFC2WTVQG2LXTRVVVRABSAIINCMHOCKFSI5ZQ4BGQ===="}, c.ExpectedEncoded("sha256", base32.StdEncoding))
+	assert.Equal(t, []string{"bznn7pvgzfuxlfc2wtvqg2lxtrvvvrabsaiincmhockfsi5zq4bgq"}, c.ExpectedEncoded("sha256", MultibaseBase32))
+	assert.Equal(t, c.ExpectedHex("sha256"), c.ExpectedEncoded("SHA256", HexEncoding))
+	assert.Equal(t, c.Expected("sha256"), c.ExpectedEncoded("sha256", base64.StdEncoding))
+	assert.Nil(t, c.ExpectedEncoded("sha512", HexEncoding))
+}
@@ -0,0 +1,138 @@
+package sri
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrLimitExceeded is returned when more content is written to a Checker than its limit allows.
+var ErrLimitExceeded = errors.New("Content exceeds the maximum allowed size")
+
+// ErrClosed is returned when writing to or checking a Checker after it has been closed.
+var ErrClosed = errors.New("Checker has been closed")
+
+// ErrSizeMismatch is returned by VerifyReaderSized and VerifyReaderAt when the content is not of the expected size.
+var ErrSizeMismatch = errors.New("Content is not of the expected size")
+
+// ErrNoData is returned by BatchChecker.VerifyAll for resources that were never written to.
+var ErrNoData = errors.New("No data was written for this resource")
+
+// ErrEmpty is returned when an SRI string contains no entries at all (for example because it is empty
+// or only contains whitespace). It is distinct from the errors below, which indicate that entries
+// were present but are invalid.
+var ErrEmpty = errors.New("Subresource integrity string contains no entries")
+
+// These errors describe why an entry in an SRI string could not be parsed.
+// They are returned wrapped in a *ParseError, so errors.Is should be used to test for them.
+var (
+	// ErrMalformedEntry is used when an entry is not of the form algorithm-digest.
+	ErrMalformedEntry = errors.New("Entry is not of the form algorithm-digest")
+	// ErrUnknownAlgorithm is used when an entry's algorithm is not supported.
+	ErrUnknownAlgorithm = errors.New("Unknown hash type")
+	// ErrInvalidEncoding is used when an entry's digest could not be decoded.
+	ErrInvalidEncoding = errors.New("Invalid base64 string")
+	// ErrWrongLength is used when an entry's digest is the wrong length for its algorithm.
+	ErrWrongLength = errors.New("Invalid digest length")
+)
+
+// A ParseError is returned when an entry in an SRI string cannot be parsed.
+type ParseError struct {
+	// Index is the index of the offending entry in the SRI string, starting from zero.
+	Index int
+	// Token is the offending entry.
+	Token string
+	// Err describes what is wrong with the entry. It wraps one of ErrMalformedEntry,
+	// ErrUnknownAlgorithm, ErrInvalidEncoding or ErrWrongLength.
+	Err error
+}
+
+// Error implements the builtin error interface.
+func (err *ParseError) Error() string {
+	return fmt.Sprintf("Invalid subresource integrity entry %d (%s): %s", err.Index, err.Token, err.Err)
+}
+
+// Unwrap returns the underlying reason for this error.
+func (err *ParseError) Unwrap() error {
+	return err.Err
+}
+
+// An IntegrityError is returned when content does not match the expected hashes.
+// Errors from parsing an SRI string are never of this type.
+//
+// The message includes the digests in hex as well as base64. The hex forms of the expected digests
+// aren't computed until Error is called, so failing a Check is cheap if the message is never used.
+type IntegrityError struct {
+	// Failures describes each of the algorithms that did not match, strongest first.
+	Failures []AlgorithmResult
+	// terse omits the hex forms of the digests from the message.
+	terse bool
+	// diagnose adds the number of leading bytes that matched when there was a single expected digest.
+	diagnose bool
+	// expected is the raw expected digests, if known, which saves decoding them again from Failures.
+	expected map[string][][]byte
+}
+
+// Error implements the builtin error interface.
+func (err *IntegrityError) Error() string {
+	msgs := make([]string, len(err.Failures))
+	for i, f := range err.Failures {
+		msgs[i] = err.describe(f)
+	}
+	return "subresource integrity failed: " + strings.Join(msgs, "; ")
+}
+
+// describe returns a description of a single failure.
+func (err *IntegrityError) describe(f AlgorithmResult) string {
+	msg := fmt.Sprintf("violated %s integrity check; was %s, expected %s", f.Algorithm, f.Computed, describeExpected(f.Expected))
+	if !err.terse {
+		msg += fmt.Sprintf(" (a.k.a. was %s, expected %s)", f.ComputedHex, describeExpected(err.expectedHex(f)))
+	}
+	if err.diagnose && len(err.Failures) == 1 && len(f.Expected) == 1 {
+		computed, _ := hex.DecodeString(f.ComputedHex)
+		expected, _ := base64.StdEncoding.DecodeString(f.Expected[0])
+		msg += fmt.Sprintf(" (first %d of %d bytes matched)", commonPrefixLength(computed, expected), len(expected))
+	}
+	return msg
+}
+
+// commonPrefixLength returns the number of leading bytes that are the same in a and b.
+func commonPrefixLength(a, b []byte) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	if len(a) < len(b) {
+		return len(a)
+	}
+	return len(b)
+}
+
+// expectedHex returns the hex forms of the expected digests for a single failure.
+func (err *IntegrityError) expectedHex(f AlgorithmResult) []string {
+	if expected, present := err.expected[f.Algorithm]; present && len(expected) == len(f.Expected) {
+		return toHex(expected)
+	}
+	return base64ToHex(f.Expected)
+}
+
+func describeExpected(expected []string) string {
+	if len(expected) == 1 {
+		return expected[0]
+	}
+	return fmt.Sprintf("one of [%s]", strings.Join(expected, ", "))
+}
+
+// base64ToHex converts a slice of base64-encoded strings to hex-encoded.
+func base64ToHex(expected []string) []string {
+	ret := make([]string, len(expected))
+	for i, e := range expected {
+		// We know these are valid because we check it in validateHash.
+		raw, _ := base64.StdEncoding.DecodeString(e)
+		ret[i] = hex.EncodeToString(raw)
+	}
+	return ret
+}
@@ -0,0 +1,122 @@
+package sri
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntegrityError(t *testing.T) {
+	c, err := NewChecker("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	err = c.Check()
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Equal(t, 1, len(ierr.Failures))
+	assert.Equal(t, "sha256", ierr.Failures[0].Algorithm)
+	assert.Equal(t, "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", ierr.Failures[0].Computed)
+	assert.Equal(t, []string{"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="}, ierr.Failures[0].Expected)
+	assert.Equal(t, "subresource integrity failed: violated sha256 integrity check; was y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, expected 49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= (a.k.a. was cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d, expected e3d870012a86bf0defe68ab63eee14da34763eff4a08c9b6546140a82c045e12)", err.Error())
+}
+
+func TestTerseErrors(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", defaultHashes(), TerseErrors())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Equal(t, "subresource integrity failed: violated sha256 integrity check; was y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, expected 49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", c.Check().Error())
+	assert.Equal(t, c.Check().Error(), c.CheckAlgorithm("sha256").Error())
+}
+
+func TestDiagnoseMismatches(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E4=", defaultHashes(), TerseErrors(), DiagnoseMismatches())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Equal(t, "subresource integrity failed: violated sha256 integrity check; was y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, expected y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E4= (first 31 of 32 bytes matched)", c.Check().Error())
+
+	c, err = NewCheckerForHashes("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", defaultHashes(), DiagnoseMismatches())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Contains(t, c.Check().Error(), "(first 0 of 32 bytes matched)")
+}
+
+func TestDiagnoseMismatchesOffByDefault(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E4=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NotContains(t, c.Check().Error(), "bytes matched")
+}
+
+func TestDiagnoseMismatchesMultipleExpected(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E4= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", defaultHashes(), DiagnoseMismatches())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NotContains(t, c.Check().Error(), "bytes matched")
+}
+
+func TestParseErrorIsNotIntegrityError(t *testing.T) {
+	_, err := NewChecker("sha256-wibblewibblewibble")
+	assert.Error(t, err)
+	var ierr *IntegrityError
+	assert.False(t, errors.As(err, &ierr))
+}
+
+func TestVerifyFileIntegrityError(t *testing.T) {
+	path := writeTempFile(t, "I want a sandwich")
+	defer os.Remove(path)
+	err := VerifyFile("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", path)
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+}
+
+func TestParseError(t *testing.T) {
+	for sri, reason := range map[string]error{
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= wibble":                                              ErrMalformedEntry,
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= md5-IdZNPlbFer1sm3bEsO3Mpw==":                        ErrUnknownAlgorithm,
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-wibble!":                                      ErrInvalidEncoding,
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=": ErrWrongLength,
+	} {
+		_, err := NewChecker(sri)
+		var perr *ParseError
+		assert.True(t, errors.As(err, &perr))
+		assert.Equal(t, 1, perr.Index)
+		assert.Equal(t, strings.Fields(sri)[1], perr.Token)
+		assert.True(t, errors.Is(err, reason))
+	}
+}
+
+func TestIntegrityErrorWithoutRawDigests(t *testing.T) {
+	// Callers can construct these themselves, in which case the hex forms are decoded from Failures.
+	err := &IntegrityError{Failures: []AlgorithmResult{{
+		Algorithm:   "sha256",
+		Computed:    "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		ComputedHex: "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d",
+		Expected:    []string{"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="},
+	}}}
+	assert.Equal(t, "subresource integrity failed: violated sha256 integrity check; was y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, expected 49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= (a.k.a. was cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d, expected e3d870012a86bf0defe68ab63eee14da34763eff4a08c9b6546140a82c045e12)", err.Error())
+}
+
+func TestCheckAgainstIntegrityErrorHex(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	err = c.CheckAgainst(map[string][]string{"sha256": {"e3d870012a86bf0defe68ab63eee14da34763eff4a08c9b6546140a82c045e12"}})
+	assert.Contains(t, err.Error(), "expected e3d870012a86bf0defe68ab63eee14da34763eff4a08c9b6546140a82c045e12)")
+}
+
+func BenchmarkCheckFailure(b *testing.B) {
+	sri := make([]string, 100)
+	for i := range sri {
+		sri[i], _ = Generate([]byte(strings.Repeat("x", i)), "sha512")
+	}
+	c, _ := NewChecker(strings.Join(sri, " "))
+	c.Write([]byte("I want a sandwich"))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Check()
+	}
+}
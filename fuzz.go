@@ -0,0 +1,23 @@
+//go:build gofuzz
+// +build gofuzz
+
+package sri
+
+// FuzzChecker is an entry point for go-fuzz (https://github.com/dvyukov/go-fuzz).
+// Run it with go-fuzz-build -func FuzzChecker.
+func FuzzChecker(data []byte) int {
+	c, err := NewChecker(string(data))
+	if err != nil {
+		if c != nil {
+			panic("NewChecker returned a Checker as well as an error")
+		}
+		return 0
+	}
+	c.Write(data)
+	c.Check()
+	// Anything we accept should round-trip through its canonical form.
+	if _, err := NewChecker(c.String()); err != nil {
+		panic("failed to reparse canonical form: " + err.Error())
+	}
+	return 1
+}
@@ -0,0 +1,100 @@
+package sri
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// defaultGenerateAlgorithm is the algorithm used by Generate if none are requested.
+const defaultGenerateAlgorithm = AlgoSHA384
+
+// Generate produces an SRI string for the given data using each of the given algorithms.
+// If no algorithms are given it defaults to sha384.
+// The supported algorithms are the same as for NewChecker; it returns an error if any others are requested.
+func Generate(data []byte, algorithms ...string) (string, error) {
+	return GenerateForHashes(data, defaultHashes(), algorithms...)
+}
+
+// GenerateFromReader is like Generate but streams the data to hash from the given reader.
+// Any error reading from it is returned.
+func GenerateFromReader(r io.Reader, algorithms ...string) (string, error) {
+	return generate(r, defaultHashes(), algorithms)
+}
+
+// GenerateForHashes is like Generate but uses the given set of hashes, similarly to NewCheckerForHashes.
+func GenerateForHashes(data []byte, hashes map[string]HashFunc, algorithms ...string) (string, error) {
+	return generate(bytes.NewReader(data), hashes, algorithms)
+}
+
+// GenerateMap is like Generate but returns a map of algorithm name to base64-encoded digest
+// instead of an assembled SRI string, which is useful for building other output formats.
+func GenerateMap(data []byte, algorithms ...string) (map[string]string, error) {
+	return generateMap(data, algorithms, base64.StdEncoding.EncodeToString)
+}
+
+// GenerateHexMap is like GenerateMap but the digests are hex-encoded.
+func GenerateHexMap(data []byte, algorithms ...string) (map[string]string, error) {
+	return generateMap(data, algorithms, hex.EncodeToString)
+}
+
+// generateMap implements GenerateMap and GenerateHexMap.
+func generateMap(data []byte, algorithms []string, encode func([]byte) string) (map[string]string, error) {
+	names, sums, err := generateSums(bytes.NewReader(data), defaultHashes(), algorithms)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(names))
+	for i, name := range names {
+		m[name] = encode(sums[i])
+	}
+	return m, nil
+}
+
+// generate implements the various Generate functions.
+func generate(r io.Reader, hashes map[string]HashFunc, algorithms []string) (string, error) {
+	names, sums, err := generateSums(r, hashes, algorithms)
+	if err != nil {
+		return "", err
+	}
+	entries := make([]string, len(names))
+	for i, name := range names {
+		entries[i] = name + "-" + base64.StdEncoding.EncodeToString(sums[i])
+	}
+	return strings.Join(entries, " "), nil
+}
+
+// generateSums reads everything from the given reader and returns the normalised names of the
+// requested algorithms along with the digest computed for each.
+func generateSums(r io.Reader, hashes map[string]HashFunc, algorithms []string) ([]string, [][]byte, error) {
+	if len(algorithms) == 0 {
+		algorithms = []string{defaultGenerateAlgorithm}
+	}
+	hashes = lowerKeys(hashes)
+	names := make([]string, len(algorithms))
+	hs := make([]hash.Hash, len(algorithms))
+	writers := make([]io.Writer, len(algorithms))
+	for i, name := range algorithms {
+		name = strings.ToLower(name)
+		names[i] = name
+		f, present := hashes[name]
+		if !present {
+			return nil, nil, fmt.Errorf("%w %s", ErrUnknownAlgorithm, name)
+		}
+		hs[i] = f()
+		writers[i] = hs[i]
+	}
+	// io.Copy reads in reasonably sized chunks so we don't need to buffer the whole input.
+	if _, err := io.Copy(io.MultiWriter(writers...), r); err != nil {
+		return nil, nil, err
+	}
+	sums := make([][]byte, len(hs))
+	for i, h := range hs {
+		sums[i] = h.Sum(nil)
+	}
+	return names, sums, nil
+}
@@ -0,0 +1,86 @@
+package sri
+
+import (
+	"crypto/md5"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	s, err := Generate([]byte("I want a sandwich"))
+	assert.NoError(t, err)
+	assert.Equal(t, "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", s)
+}
+
+func TestGenerateMultiple(t *testing.T) {
+	s, err := Generate([]byte("I want a sandwich"), "sha256", "sha512")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", s)
+	// It should of course be verifiable by a Checker.
+	c, err := NewChecker(s)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestGenerateUnknownHash(t *testing.T) {
+	_, err := Generate([]byte("I want a sandwich"), "sha256", "md5")
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
+
+func TestGenerateForHashes(t *testing.T) {
+	s, err := GenerateForHashes([]byte("I want a sandwich"), map[string]HashFunc{"md5": md5.New}, "md5")
+	assert.NoError(t, err)
+	assert.Equal(t, "md5-IdZNPlbFer1sm3bEsO3Mpw==", s)
+}
+
+func TestGenerateFromReader(t *testing.T) {
+	s, err := GenerateFromReader(strings.NewReader("I want a sandwich"), "sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", s)
+}
+
+func TestGenerateFromReaderError(t *testing.T) {
+	_, err := GenerateFromReader(iotest.TimeoutReader(strings.NewReader("I want a sandwich")))
+	assert.Equal(t, iotest.ErrTimeout, err)
+}
+
+func TestGenerateMixedCase(t *testing.T) {
+	s, err := Generate([]byte("I want a sandwich"), "SHA256")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", s)
+}
+
+func TestGenerateWithConstants(t *testing.T) {
+	s, err := Generate([]byte("I want a sandwich"), AlgoSHA256)
+	assert.NoError(t, err)
+	c, err := NewChecker(s)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected(AlgoSHA256))
+}
+
+func TestGenerateMap(t *testing.T) {
+	m, err := GenerateMap([]byte("I want a sandwich"), "sha256", "SHA512")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"sha256": "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha512": "xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==",
+	}, m)
+}
+
+func TestGenerateHexMap(t *testing.T) {
+	m, err := GenerateHexMap([]byte("I want a sandwich"), "sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"sha256": "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d",
+	}, m)
+}
+
+func TestGenerateMapUnknownHash(t *testing.T) {
+	_, err := GenerateMap([]byte("I want a sandwich"), "md5")
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
@@ -0,0 +1,27 @@
+package sri
+
+import "strings"
+
+// htmlUnescaper replaces the HTML character references that can plausibly appear in an integrity
+// attribute. It deliberately doesn't handle anything else; a single pass is made so nothing is
+// unescaped twice.
+var htmlUnescaper = strings.NewReplacer(
+	"&amp;", "&",
+	"&quot;", `"`, "&#34;", `"`, "&#x22;", `"`,
+	"&apos;", "'", "&#39;", "'", "&#x27;", "'",
+	"&plus;", "+", "&#43;", "+", "&#x2B;", "+", "&#x2b;", "+",
+	"&sol;", "/", "&#47;", "/", "&#x2F;", "/", "&#x2f;", "/",
+	"&equals;", "=", "&#61;", "=", "&#x3D;", "=", "&#x3d;", "=",
+)
+
+// ParseHTMLIntegrity creates a new Checker from the value of an HTML integrity attribute as it
+// might be scraped from a page. Character references for the characters that appear in SRI strings
+// (e.g. &#43; for +) are unescaped, and surrounding whitespace and a matching pair of surrounding
+// quotes are removed, before it is parsed in the same way as NewChecker.
+func ParseHTMLIntegrity(attr string) (*Checker, error) {
+	attr = strings.TrimSpace(htmlUnescaper.Replace(attr))
+	if len(attr) >= 2 && (attr[0] == '"' || attr[0] == '\'') && attr[len(attr)-1] == attr[0] {
+		attr = attr[1 : len(attr)-1]
+	}
+	return NewChecker(attr)
+}
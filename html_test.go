@@ -0,0 +1,40 @@
+package sri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseHTMLIntegrity(t *testing.T) {
+	for _, attr := range []string{
+		`sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j`,
+		`"sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"`,
+		` 'sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j' `,
+		`sha384-4QuseiT9WQ&#43;80EDZ&#x2F;MYTodasdNBTLIC&sol;9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j`,
+		`&quot;sha384-4QuseiT9WQ&#x2b;80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j&quot;`,
+	} {
+		c, err := ParseHTMLIntegrity(attr)
+		if assert.NoError(t, err, attr) {
+			c.Write([]byte("I want a sandwich"))
+			assert.NoError(t, c.Check())
+		}
+	}
+}
+
+func TestParseHTMLIntegrityPadding(t *testing.T) {
+	c, err := ParseHTMLIntegrity(`"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0&#61;"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", c.String())
+}
+
+func TestParseHTMLIntegrityNoDoubleUnescape(t *testing.T) {
+	// &amp;#43; is the escaped form of the literal text "&#43;", not of "+".
+	_, err := ParseHTMLIntegrity(`sha384-4QuseiT9WQ&amp;#43;80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j`)
+	assert.Error(t, err)
+}
+
+func TestParseHTMLIntegrityMismatchedQuotes(t *testing.T) {
+	_, err := ParseHTMLIntegrity(`"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0='`)
+	assert.Error(t, err)
+}
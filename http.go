@@ -0,0 +1,184 @@
+package sri
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// IntegrityHeader is the request header that a Transport reads the expected integrity from.
+const IntegrityHeader = "X-Expected-Integrity"
+
+// A Transport is an http.RoundTripper that verifies response bodies against an SRI string.
+type Transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport returns a new Transport wrapping the given one, or http.DefaultTransport if it is nil.
+//
+// Requests that have an X-Expected-Integrity header have their response bodies verified against
+// its value; reading the body to EOF returns an error if it doesn't match.
+// The header is removed before the request is passed on. Requests without it are passed through unchanged.
+//
+// Note that SRI applies to the decoded content. The base transport is expected to handle any content
+// encoding (http.Transport decompresses gzip itself unless the caller requested it explicitly); see
+// NewDecodingVerifyingReader for verifying a body that is still encoded.
+func NewTransport(base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{base: base}
+}
+
+// RoundTrip implements the http.RoundTripper interface.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	sri := req.Header.Get(IntegrityHeader)
+	if sri == "" {
+		return t.base.RoundTrip(req)
+	}
+	c, err := NewChecker(sri)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid %s header: %w", IntegrityHeader, err)
+	}
+	// RoundTrippers mustn't modify the request, so clone it before removing the header.
+	req = req.Clone(req.Context())
+	req.Header.Del(IntegrityHeader)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &verifyingReader{r: resp.Body, c: c, closer: resp.Body}
+	return resp, nil
+}
+
+// VerifyURL fetches the given URL and verifies the response body against the given SRI string.
+// If client is nil, http.DefaultClient is used. It stops and returns the context's error if the
+// context is cancelled.
+//
+// If the content doesn't match, the error is an *IntegrityError (and can be tested for with
+// errors.As); any other error indicates that the SRI string was invalid or that fetching the URL
+// failed, including if the server returned a non-2xx status.
+func VerifyURL(ctx context.Context, client *http.Client, url, sri string) error {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("Failed to fetch %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("Failed to fetch %s: %s", url, resp.Status)
+	}
+	return c.VerifyContext(ctx, resp.Body)
+}
+
+// ParseLinkIntegrity creates a new Checker from the integrity parameter of an HTTP Link header
+// value, such as one carrying a preload hint (e.g. `</app.js>; rel=preload; as=script; integrity="sha384-..."`).
+// The value is parsed according to the grammar in RFC 8288, so parameters can be quoted or not
+// and commas and semicolons inside quotes or the target URI are handled correctly.
+// It returns an error if the value is malformed, if none of its links has an integrity parameter,
+// or if more than one does (in which case it's ambiguous which one should be used).
+func ParseLinkIntegrity(linkHeader string) (*Checker, error) {
+	links, err := parseLinkHeader(linkHeader)
+	if err != nil {
+		return nil, err
+	}
+	sri := ""
+	found := false
+	for _, params := range links {
+		if value, present := params["integrity"]; present {
+			if found {
+				return nil, fmt.Errorf("Link header contains more than one integrity parameter")
+			}
+			sri = value
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("Link header does not contain an integrity parameter")
+	}
+	return NewChecker(sri)
+}
+
+// parseLinkHeader parses the value of a Link header into the parameters of each of its links.
+// Parameter names are lowercased; if a name appears more than once in a link, the first one wins.
+func parseLinkHeader(header string) ([]map[string]string, error) {
+	var links []map[string]string
+	s := strings.TrimSpace(header)
+	for s != "" {
+		if s[0] == ',' {
+			s = strings.TrimLeft(s[1:], " \t")
+			continue
+		} else if s[0] != '<' {
+			return nil, fmt.Errorf("Invalid Link header: expected '<' at %q", s)
+		}
+		end := strings.IndexByte(s, '>')
+		if end == -1 {
+			return nil, fmt.Errorf("Invalid Link header: unterminated target URI")
+		}
+		s = strings.TrimLeft(s[end+1:], " \t")
+		params := map[string]string{}
+		for s != "" && s[0] == ';' {
+			s = strings.TrimLeft(s[1:], " \t")
+			end := strings.IndexAny(s, "=;, \t")
+			if end == -1 {
+				end = len(s)
+			}
+			name := strings.ToLower(s[:end])
+			s = strings.TrimLeft(s[end:], " \t")
+			value := ""
+			if s != "" && s[0] == '=' {
+				s = strings.TrimLeft(s[1:], " \t")
+				var err error
+				if value, s, err = parseLinkParamValue(s); err != nil {
+					return nil, err
+				}
+				s = strings.TrimLeft(s, " \t")
+			}
+			if _, present := params[name]; !present && name != "" {
+				params[name] = value
+			}
+		}
+		if s != "" && s[0] != ',' {
+			return nil, fmt.Errorf("Invalid Link header: unexpected %q", s)
+		}
+		links = append(links, params)
+	}
+	return links, nil
+}
+
+// parseLinkParamValue parses a single parameter value, which is either a token or a quoted string,
+// from the start of s. It returns the value and the remainder of s.
+func parseLinkParamValue(s string) (string, string, error) {
+	if s == "" || s[0] != '"' {
+		end := strings.IndexAny(s, ";, \t")
+		if end == -1 {
+			end = len(s)
+		}
+		return s[:end], s[end:], nil
+	}
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			return b.String(), s[i+1:], nil
+		case '\\':
+			if i++; i < len(s) {
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", fmt.Errorf("Invalid Link header: unterminated quoted string")
+}
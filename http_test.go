@@ -0,0 +1,118 @@
+package sri
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "", r.Header.Get(IntegrityHeader))
+		w.Write([]byte("I want a sandwich"))
+	}))
+	defer s.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	get := func(sri string) ([]byte, error) {
+		req, err := http.NewRequest(http.MethodGet, s.URL, nil)
+		assert.NoError(t, err)
+		if sri != "" {
+			req.Header.Set(IntegrityHeader, sri)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	b, err := get("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Equal(t, "I want a sandwich", string(b))
+
+	_, err = get("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+
+	_, err = get("wibble")
+	assert.Error(t, err)
+
+	b, err = get("")
+	assert.NoError(t, err)
+	assert.Equal(t, "I want a sandwich", string(b))
+}
+
+func TestVerifyURL(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("I want a sandwich"))
+	}))
+	defer s.Close()
+	ctx := context.Background()
+
+	assert.NoError(t, VerifyURL(ctx, nil, s.URL, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="))
+	assert.NoError(t, VerifyURL(ctx, s.Client(), s.URL, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="))
+
+	err := VerifyURL(ctx, nil, s.URL, "sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+
+	err = VerifyURL(ctx, nil, s.URL+"/missing", "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &ierr))
+	assert.Contains(t, err.Error(), "404")
+
+	assert.Error(t, VerifyURL(ctx, nil, s.URL, "wibble"))
+}
+
+func TestVerifyURLCancelled(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("I want a sandwich"))
+	}))
+	defer s.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := VerifyURL(ctx, nil, s.URL, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestParseLinkIntegrity(t *testing.T) {
+	for _, link := range []string{
+		`</sandwich.js>; rel=preload; as=script; integrity="sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="`,
+		`</sandwich.js>;rel=preload;integrity=sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=;crossorigin`,
+		`</a,b;c.js>; rel="preload; really"; Integrity = "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", </style.css>; rel=preload; as=style`,
+		`</sandwich.js>; title="a \"quoted\" title"; integrity="sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="`,
+	} {
+		c, err := ParseLinkIntegrity(link)
+		assert.NoError(t, err, link)
+		if err == nil {
+			c.Write([]byte("I want a sandwich"))
+			assert.NoError(t, c.Check(), link)
+		}
+	}
+}
+
+func TestParseLinkIntegrityErrors(t *testing.T) {
+	for _, link := range []string{
+		``,
+		`</sandwich.js>; rel=preload`,
+		`</sandwich.js>; rel=preload; integrity="sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`,
+		`</sandwich.js; integrity=sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`,
+		`sandwich.js; integrity=sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`,
+		`</a.js>; integrity=sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, </b.js>; integrity=sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=`,
+		`</sandwich.js>; integrity="wibble"`,
+	} {
+		_, err := ParseLinkIntegrity(link)
+		assert.Error(t, err, link)
+	}
+}
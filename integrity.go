@@ -0,0 +1,161 @@
+package sri
+
+import (
+	"bytes"
+	"encoding/json"
+	"hash"
+	"sort"
+	"strings"
+)
+
+// An Integrity is a parsed and validated SRI string, from which Checkers can be created.
+//
+// It is immutable once created and so is safe for concurrent use. It is useful when checking many
+// resources against the same SRI string, since creating a Checker from it doesn't re-parse the string.
+type Integrity struct {
+	// template is a Checker that we copy expected values and options from. It is never written to.
+	template *Checker
+}
+
+// Parse parses the given SRI string into an Integrity.
+// It supports the same set of algorithms as NewChecker.
+func Parse(sri string) (*Integrity, error) {
+	return ParseForHashes(sri, defaultHashes())
+}
+
+// ParseForHashes is like Parse but uses the given set of hashes and options, similarly to NewCheckerForHashes.
+func ParseForHashes(sri string, hashes map[string]HashFunc, options ...Option) (*Integrity, error) {
+	c, err := NewCheckerForHashes(sri, hashes, options...)
+	if err != nil {
+		return nil, err
+	}
+	return &Integrity{template: c}, nil
+}
+
+// NewChecker creates a new Checker from this Integrity.
+// It is equivalent to calling NewChecker with the original SRI string, but never fails.
+func (i *Integrity) NewChecker() *Checker {
+	return i.template.copy()
+}
+
+// String returns the canonical SRI string for this Integrity, in the same form as Checker.String.
+func (i *Integrity) String() string {
+	return i.template.String()
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+// It produces an object mapping each algorithm to a list of its base64-encoded digests,
+// e.g. {"sha256":["..."],"sha512":["..."]}.
+func (i *Integrity) MarshalJSON() ([]byte, error) {
+	m := make(map[string][]string, len(i.template.expected))
+	for name, expected := range i.template.expected {
+		m[name] = toBase64(expected)
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+// It accepts either the object form produced by MarshalJSON or a plain SRI string, and supports
+// the same set of algorithms as Parse.
+//
+// Since the hashes and options an Integrity was created with aren't part of its JSON form, one
+// created by ParseForHashes with algorithms outside that set doesn't round-trip: unmarshalling it
+// fails with an error wrapping ErrUnknownAlgorithm rather than dropping those algorithms.
+func (i *Integrity) UnmarshalJSON(data []byte) error {
+	var sri string
+	if err := json.Unmarshal(data, &sri); err != nil {
+		var m map[string][]string
+		if err := json.Unmarshal(data, &m); err != nil {
+			return err
+		}
+		var entries []string
+		for name, values := range m {
+			for _, value := range values {
+				entries = append(entries, name+"-"+value)
+			}
+		}
+		sri = strings.Join(entries, " ")
+	}
+	parsed, err := Parse(sri)
+	if err != nil {
+		return err
+	}
+	*i = *parsed
+	return nil
+}
+
+// Equivalent returns true if the two given SRI strings contain the same set of digests for each algorithm.
+// It ignores the order of entries and how the digests are encoded.
+// It supports the same set of algorithms as NewChecker, and returns an error if either string is invalid.
+func Equivalent(a, b string) (bool, error) {
+	ia, err := Parse(a)
+	if err != nil {
+		return false, err
+	}
+	ib, err := Parse(b)
+	if err != nil {
+		return false, err
+	}
+	return equalExpected(ia.template.expected, ib.template.expected), nil
+}
+
+// CanonicalizeSRI returns the canonical form of the given SRI string: deduplicated, strongest
+// algorithm first, single-space-separated and using padded standard base64. Multiple digests for
+// the same algorithm are sorted so that the result is byte-stable regardless of their original order.
+// Any options on the entries are dropped.
+// It supports the same set of algorithms as NewChecker, and returns an error if the string is invalid.
+func CanonicalizeSRI(sri string) (string, error) {
+	c := newChecker(defaultHashes())
+	c.sizes = defaultSizes
+	if err := c.parse(sri); err != nil {
+		return "", err
+	}
+	for _, expected := range c.expected {
+		sort.Slice(expected, func(i, j int) bool {
+			return bytes.Compare(expected[i], expected[j]) < 0
+		})
+	}
+	return c.String(), nil
+}
+
+// equalExpected returns true if the two given sets of expected digests are the same.
+// It assumes that there are no duplicate digests within each algorithm (which addHash ensures).
+func equalExpected(a, b map[string][][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, expectedA := range a {
+		expectedB := b[name]
+		if len(expectedA) != len(expectedB) {
+			return false
+		}
+		for _, value := range expectedA {
+			if !containsExact(expectedB, value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// copy returns a copy of this Checker with the same expected values and options, but none of
+// its hash state; i.e. as it was when it was created.
+func (c *Checker) copy() *Checker {
+	n := *c
+	n.expected = make(map[string][][]byte, len(c.expected))
+	for name, expected := range c.expected {
+		n.expected[name] = append([][]byte{}, expected...)
+	}
+	n.options = make(map[string][]string, len(c.options))
+	for name, options := range c.options {
+		n.options[name] = append([]string{}, options...)
+	}
+	n.funcs = make(map[string]HashFunc, len(c.funcs))
+	for name, f := range c.funcs {
+		n.funcs[name] = f
+	}
+	n.hashes = make(map[string]hash.Hash, len(c.hashes))
+	n.buf = nil // Don't share this, the two might be used concurrently.
+	n.Reset()
+	return &n
+}
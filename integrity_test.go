@@ -0,0 +1,125 @@
+package sri
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	i, err := Parse("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	c1 := i.NewChecker()
+	c2 := i.NewChecker()
+	c1.Write([]byte("I want a sandwich"))
+	c2.Write([]byte("I want a burrito"))
+	assert.NoError(t, c1.Check())
+	assert.Error(t, c2.Check())
+	assert.NoError(t, i.NewChecker().Verify(strings.NewReader("I want a sandwich")))
+	assert.Equal(t, "sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", i.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse("wibble")
+	assert.Error(t, err)
+}
+
+func TestParseForHashes(t *testing.T) {
+	i, err := ParseForHashes("md5-AAAAAAAAAAAAAAAAAAAAAA== sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=", map[string]HashFunc{
+		"md5":  md5.New,
+		"sha1": sha1.New,
+	}, StrongestOnly())
+	assert.NoError(t, err)
+	assert.NoError(t, i.NewChecker().Verify(strings.NewReader("I want a sandwich")))
+}
+
+func TestEquivalent(t *testing.T) {
+	eq, err := Equivalent(
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=",
+		"sha384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	)
+	assert.NoError(t, err)
+	assert.True(t, eq)
+}
+
+func TestNotEquivalent(t *testing.T) {
+	eq, err := Equivalent(
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	)
+	assert.NoError(t, err)
+	assert.False(t, eq)
+	eq, err = Equivalent(
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=",
+	)
+	assert.NoError(t, err)
+	assert.False(t, eq)
+}
+
+func TestEquivalentInvalid(t *testing.T) {
+	_, err := Equivalent("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "wibble")
+	assert.Error(t, err)
+	_, err = Equivalent("wibble", "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.Error(t, err)
+}
+
+func TestCanonicalizeSRI(t *testing.T) {
+	const canonical = "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="
+	for _, sri := range []string{
+		canonical,
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=",
+		"SHA384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j\n\tsha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI, sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=?foo sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	} {
+		s, err := CanonicalizeSRI(sri)
+		assert.NoError(t, err)
+		assert.Equal(t, canonical, s)
+	}
+	_, err := CanonicalizeSRI("wibble")
+	assert.Error(t, err)
+}
+
+func TestIntegrityMarshalJSON(t *testing.T) {
+	i, err := Parse("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI")
+	assert.NoError(t, err)
+	b, err := json.Marshal(i)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"sha256":["y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=","49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="],"sha512":["xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="]}`, string(b))
+	// It should round-trip.
+	var i2 Integrity
+	assert.NoError(t, json.Unmarshal(b, &i2))
+	assert.Equal(t, i.String(), i2.String())
+}
+
+func TestIntegrityMarshalJSONCustomHashes(t *testing.T) {
+	i, err := ParseForHashes("md5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{"md5": md5.New})
+	assert.NoError(t, err)
+	b, err := json.Marshal(i)
+	assert.NoError(t, err)
+	var i2 Integrity
+	assert.True(t, errors.Is(json.Unmarshal(b, &i2), ErrUnknownAlgorithm))
+}
+
+func TestIntegrityUnmarshalJSONString(t *testing.T) {
+	var s struct {
+		Integrity *Integrity `json:"integrity"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(`{"integrity": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}`), &s))
+	c := s.Integrity.NewChecker()
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestIntegrityUnmarshalJSONInvalid(t *testing.T) {
+	var i Integrity
+	assert.Error(t, json.Unmarshal([]byte(`"wibble"`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`{"sha256":["wibble"]}`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`{"md5":["IdZNPlbFer1sm3bEsO3Mpw=="]}`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`42`), &i))
+	assert.Error(t, json.Unmarshal([]byte(`{}`), &i))
+}
@@ -0,0 +1,56 @@
+package sri
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewCheckerFromReader creates a new Checker from SRI entries read from the given reader, which
+// would typically be a file containing an integrity manifest.
+// Blank lines and lines beginning with '#' are ignored; all other lines can contain one or more
+// entries in the same format that NewChecker accepts (which also determines the supported algorithms).
+func NewCheckerFromReader(r io.Reader) (*Checker, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewChecker(strings.Join(lines, " "))
+}
+
+// NewCheckerFromJSON creates a new Checker from an SRI string held in a field of the given JSON
+// object, as found in many package managers' lockfiles (e.g. {"integrity": "sha512-..."}).
+// The path gives the keys of the field to use, descending through nested objects; if it's empty,
+// the top-level "integrity" field is used.
+// It returns an error if the field is missing or isn't a string, and otherwise supports the same
+// set of algorithms as NewChecker.
+func NewCheckerFromJSON(data []byte, path ...string) (*Checker, error) {
+	if len(path) == 0 {
+		path = []string{"integrity"}
+	}
+	value := json.RawMessage(data)
+	for i, key := range path {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return nil, fmt.Errorf("Failed to read JSON object at %s: %w", strings.Join(path[:i], "."), err)
+		}
+		v, present := obj[key]
+		if !present {
+			return nil, fmt.Errorf("JSON does not contain field %s", strings.Join(path[:i+1], "."))
+		}
+		value = v
+	}
+	var sri string
+	if err := json.Unmarshal(value, &sri); err != nil {
+		return nil, fmt.Errorf("JSON field %s is not a string: %w", strings.Join(path, "."), err)
+	}
+	return NewChecker(sri)
+}
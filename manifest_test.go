@@ -0,0 +1,62 @@
+package sri
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCheckerFromReader(t *testing.T) {
+	c, err := NewCheckerFromReader(strings.NewReader(`
+# This is the integrity manifest for our sandwich.
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+
+    # Indented comments are fine too.
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha384", "sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestNewCheckerFromReaderOnlyComments(t *testing.T) {
+	_, err := NewCheckerFromReader(strings.NewReader("# sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=\n\n"))
+	assert.True(t, errors.Is(err, ErrEmpty))
+}
+
+func TestNewCheckerFromReaderError(t *testing.T) {
+	_, err := NewCheckerFromReader(iotest.TimeoutReader(strings.NewReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=\n")))
+	assert.Equal(t, iotest.ErrTimeout, err)
+}
+
+func TestNewCheckerFromJSON(t *testing.T) {
+	c, err := NewCheckerFromJSON([]byte(`{"version": "1.0.0", "integrity": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}`))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestNewCheckerFromJSONPath(t *testing.T) {
+	c, err := NewCheckerFromJSON([]byte(`{"packages": {"sandwich": {"dist": {"integrity": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}}}}`), "packages", "sandwich", "dist", "integrity")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha256"}, c.Algorithms())
+}
+
+func TestNewCheckerFromJSONErrors(t *testing.T) {
+	_, err := NewCheckerFromJSON([]byte(`{"version": "1.0.0"}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "does not contain field integrity")
+	_, err = NewCheckerFromJSON([]byte(`{"integrity": 42}`))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not a string")
+	_, err = NewCheckerFromJSON([]byte(`{"dist": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}`), "dist", "integrity")
+	assert.Error(t, err)
+	_, err = NewCheckerFromJSON([]byte(`wibble`))
+	assert.Error(t, err)
+	_, err = NewCheckerFromJSON([]byte(`{"integrity": "wibble"}`))
+	assert.Error(t, err)
+}
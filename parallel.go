@@ -0,0 +1,39 @@
+package sri
+
+import (
+	"hash"
+	"sync"
+)
+
+// A parallelWriter writes to a set of hashes, hashing large writes concurrently.
+//
+// Hashing is CPU-bound, so when several algorithms are in use and the input is large this can
+// be considerably faster on a multi-core machine than io.MultiWriter, which writes to each in turn.
+// For small writes the overhead of starting goroutines outweighs the benefit, so those are written
+// sequentially.
+type parallelWriter struct {
+	hashes    []hash.Hash
+	threshold int
+}
+
+// Write implements the io.Writer interface.
+// It never returns an error, since hashes never do.
+func (w *parallelWriter) Write(b []byte) (int, error) {
+	if len(b) < w.threshold {
+		for _, h := range w.hashes {
+			h.Write(b)
+		}
+		return len(b), nil
+	}
+	var wg sync.WaitGroup
+	wg.Add(len(w.hashes) - 1)
+	for _, h := range w.hashes[1:] {
+		go func(h hash.Hash) {
+			defer wg.Done()
+			h.Write(b)
+		}(h)
+	}
+	w.hashes[0].Write(b)
+	wg.Wait()
+	return len(b), nil
+}
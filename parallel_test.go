@@ -0,0 +1,55 @@
+package sri
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelHashing(t *testing.T) {
+	c, err := NewCheckerForHashes(benchmarkSRI, defaultHashes(), WithParallelHashing(4))
+	assert.NoError(t, err)
+	// Mix small and large writes so both paths are exercised.
+	c.Write([]byte("I w"))
+	c.Write([]byte("ant a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"sha512", "sha384", "sha256"}, c.Matched())
+}
+
+func TestParallelHashingSingleAlgorithm(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", defaultHashes(), WithParallelHashing(1))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestParallelHashingReset(t *testing.T) {
+	c, err := NewCheckerForHashes(benchmarkSRI, defaultHashes(), WithParallelHashing(1))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a burrito"))
+	assert.Error(t, c.Check())
+	c.Reset()
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func BenchmarkSequentialMultiHashWrite(b *testing.B) {
+	c, _ := NewChecker(benchmarkSRI)
+	input := bytes.Repeat([]byte("I want a sandwich"), 1024*1024)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Write(input)
+	}
+}
+
+func BenchmarkParallelMultiHashWrite(b *testing.B) {
+	c, _ := NewCheckerForHashes(benchmarkSRI, defaultHashes(), WithParallelHashing(DefaultParallelThreshold))
+	input := bytes.Repeat([]byte("I want a sandwich"), 1024*1024)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Write(input)
+	}
+}
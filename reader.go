@@ -0,0 +1,97 @@
+package sri
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// NewVerifyingReader returns a reader that reads from the given one and verifies the content
+// against the given SRI string. When the underlying reader reaches EOF, Read returns an error if the
+// content didn't match instead of io.EOF, so anything consuming it (e.g. io.Copy) will see the failure.
+//
+// If the given reader is also an io.Closer, closing the returned reader closes it too.
+func NewVerifyingReader(sri string, r io.Reader) (io.ReadCloser, error) {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return nil, err
+	}
+	vr := &verifyingReader{r: r, c: c}
+	if closer, ok := r.(io.Closer); ok {
+		vr.closer = closer
+	}
+	return vr, nil
+}
+
+// NewDecodingVerifyingReader is like NewVerifyingReader but first decodes the content according to the
+// given content encoding (as found in an HTTP Content-Encoding header), so that the decoded content is
+// verified rather than the encoded bytes. This matches how browsers apply SRI to HTTP responses.
+//
+// The supported encodings are gzip (or x-gzip) and identity; an empty encoding is treated as identity.
+// For gzip the header is read immediately, so an error is returned if it's invalid.
+func NewDecodingVerifyingReader(sri, encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return NewVerifyingReader(sri, r)
+	case "gzip", "x-gzip":
+		c, err := NewChecker(sri)
+		if err != nil {
+			return nil, err
+		}
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		cs := closers{gz}
+		if closer, ok := r.(io.Closer); ok {
+			cs = append(cs, closer)
+		}
+		return &verifyingReader{r: gz, c: c, closer: cs}, nil
+	default:
+		return nil, fmt.Errorf("Unsupported content encoding %s", encoding)
+	}
+}
+
+// closers is a set of io.Closers that are closed together.
+type closers []io.Closer
+
+// Close implements the io.Closer interface. It closes all of them, returning the first error.
+func (cs closers) Close() error {
+	var err error
+	for _, c := range cs {
+		if e := c.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// A verifyingReader wraps a reader, passing everything read through a Checker, and checks it
+// when the underlying reader reaches EOF.
+type verifyingReader struct {
+	r      io.Reader
+	c      *Checker
+	closer io.Closer
+}
+
+// Read implements the io.Reader interface.
+// On reaching EOF it returns an error from the Checker if the content didn't match.
+func (r *verifyingReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	r.c.Write(b[:n])
+	if err == io.EOF {
+		if err := r.c.Check(); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+// Close implements the io.Closer interface.
+func (r *verifyingReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
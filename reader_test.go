@@ -0,0 +1,103 @@
+package sri
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyingReader(t *testing.T) {
+	r, err := NewVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a sandwich"))
+	assert.NoError(t, err)
+	var buf bytes.Buffer
+	n, err := io.Copy(&buf, r)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 17, n)
+	assert.Equal(t, "I want a sandwich", buf.String())
+	assert.NoError(t, r.Close())
+}
+
+func TestVerifyingReaderFailure(t *testing.T) {
+	r, err := NewVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a burrito"))
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+}
+
+func TestVerifyingReaderInvalid(t *testing.T) {
+	_, err := NewVerifyingReader("wibble", strings.NewReader("I want a sandwich"))
+	assert.Error(t, err)
+}
+
+func TestVerifyingReaderClose(t *testing.T) {
+	rc := &closeRecorder{Reader: strings.NewReader("I want a sandwich")}
+	r, err := NewVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", rc)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.True(t, rc.closed)
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+// gzipped returns the gzip-compressed form of the given string.
+func gzipped(t *testing.T, s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write([]byte(s))
+	assert.NoError(t, err)
+	assert.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+func TestDecodingVerifyingReader(t *testing.T) {
+	rc := &closeRecorder{Reader: bytes.NewReader(gzipped(t, "I want a sandwich"))}
+	r, err := NewDecodingVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "GZIP", rc)
+	assert.NoError(t, err)
+	b, err := ioutil.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Equal(t, "I want a sandwich", string(b))
+	assert.NoError(t, r.Close())
+	assert.True(t, rc.closed)
+}
+
+func TestDecodingVerifyingReaderFailure(t *testing.T) {
+	r, err := NewDecodingVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "gzip", bytes.NewReader(gzipped(t, "I want a burrito")))
+	assert.NoError(t, err)
+	_, err = ioutil.ReadAll(r)
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+}
+
+func TestDecodingVerifyingReaderIdentity(t *testing.T) {
+	for _, encoding := range []string{"", "identity"} {
+		r, err := NewDecodingVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", encoding, strings.NewReader("I want a sandwich"))
+		assert.NoError(t, err)
+		_, err = ioutil.ReadAll(r)
+		assert.NoError(t, err)
+	}
+}
+
+func TestDecodingVerifyingReaderErrors(t *testing.T) {
+	_, err := NewDecodingVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "br", strings.NewReader("I want a sandwich"))
+	assert.Error(t, err)
+	// This isn't gzipped.
+	_, err = NewDecodingVerifyingReader("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "gzip", strings.NewReader("I want a sandwich"))
+	assert.Error(t, err)
+	_, err = NewDecodingVerifyingReader("wibble", "gzip", bytes.NewReader(gzipped(t, "I want a sandwich")))
+	assert.Error(t, err)
+}
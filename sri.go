@@ -3,170 +3,1454 @@
 package sri
 
 import (
+	"bytes"
+	"context"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
+	"sort"
 	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
 )
 
 // A Checker implements checking of a resource against a given subresource integrity string.
 //
 // It is not safe for concurrent use; each Checker corresponds to a single resource to be checked.
+// The exception is the methods that only describe the expected values (Expected, ExpectedHex,
+// Algorithms, Has, Count, Options and String), which can be called concurrently with one another
+// as long as nothing modifies the Checker (i.e. Add) at the same time.
+// Use a SyncChecker if you need to share one between goroutines.
 //
 // After creation you would typically use it as a Writer to add data to it, then call Check to
 // verify that the content matches the original expression.
+//
+// Content is hashed strictly in the order it is written, so a Checker is deliberately only an
+// io.Writer (plus io.StringWriter and io.ReaderFrom, which are equivalent to sequential writes);
+// it will never implement io.WriterAt or io.Seeker, since hashing can't be done out of order.
+// Generic code that type-asserts for those will fall back to plain sequential writes.
 type Checker struct {
-	expected map[string][]string
-	hashes   map[string]hash.Hash
-	w        io.Writer
+	expected  map[string][][]byte
+	hashes    map[string]hash.Hash
+	funcs     map[string]HashFunc
+	supported map[string]HashFunc
+	options   map[string][]string
+	w         io.Writer
+	mode      mode
+	matched   []string
+	verified  string
+	written   int64
+	limit     int64
+	exceeded  bool
+	minimum   string
+	priority  []string
+	sizes     map[string]int
+	buf       []byte
+	terse     bool
+	diagnose  bool
+	lenient   bool
+	parallel  int
+	extra     []string
+	encoding  *base64.Encoding
+	onResult  func(algorithm string, matched bool, computed string)
+	closed    bool
 }
 
+// Names of the hash algorithms that this package supports out of the box.
+// These are untyped constants so they can be passed anywhere an algorithm name is accepted.
+const (
+	AlgoSHA1    = "sha1"
+	AlgoSHA256  = "sha256"
+	AlgoSHA384  = "sha384"
+	AlgoSHA512  = "sha512"
+	AlgoSHA3256 = "sha3-256"
+	AlgoSHA3384 = "sha3-384"
+	AlgoSHA3512 = "sha3-512"
+
+	// The truncated SHA-512 variants use an underscore since their conventional names
+	// (e.g. SHA-512/256) contain characters that aren't valid in an SRI string.
+	AlgoSHA512_256 = "sha512_256"
+	AlgoSHA512_224 = "sha512_224"
+
+	AlgoBLAKE2b256 = "blake2b-256"
+	AlgoBLAKE2b384 = "blake2b-384"
+	AlgoBLAKE2b512 = "blake2b-512"
+	AlgoBLAKE2s256 = "blake2s-256"
+)
+
 // A HashFunc is simply a function that returns a new Hash instance.
+//
+// The returned Hash must honour the hash.Hash contract that Sum does not change its state, since
+// Checkers may call it more than once (for example if Check is called repeatedly).
+// All the standard library hashes do so.
 type HashFunc func() hash.Hash
 
+// An Option configures optional behaviour of a Checker when it is created.
+type Option func(*Checker)
+
+// mode describes how a Checker decides whether the content matches.
+type mode int
+
+const (
+	// allMustMatch requires every algorithm present to have at least one matching value.
+	allMustMatch mode = iota
+	// strongestOnly checks only the strongest algorithm present and ignores the rest.
+	strongestOnly
+	// anyMatch requires at least one algorithm present to have a matching value.
+	anyMatch
+)
+
+// maxEntryLength is the longest entry we'll accept in an SRI string. This is far longer than any
+// sensible digest, but bounds how much work we do on pathological input.
+const maxEntryLength = 1024
+
+// copyBufferSize is the size of buffer we use when reading from readers ourselves.
+const copyBufferSize = 32 * 1024
+
+// defaultPriority is the order in which we prefer hash algorithms, strongest first.
+// Any algorithms not listed here rank below all of these.
+var defaultPriority = []string{AlgoSHA512, AlgoSHA384, AlgoSHA256, AlgoSHA1}
+
+// AllMustMatch returns an Option that makes the Checker require every algorithm present in the
+// SRI string to have at least one matching value. This is the default, but can be given explicitly
+// to make it clear that it is intended.
+//
+// Note that this is stricter than the SRI spec, which only considers the strongest algorithm;
+// for example, given "sha256-<bad> sha512-<good>" a browser would accept the content but this would not.
+func AllMustMatch() Option {
+	return func(c *Checker) {
+		c.mode = allMustMatch
+	}
+}
+
+// StrongestOnly returns an Option that makes the Checker validate only against the strongest
+// algorithm present in the SRI string, ignoring any others.
+// This is the behaviour described in the SRI spec, where weaker algorithms are present as
+// fallbacks for user agents that don't support the stronger ones.
+// Only the strongest algorithm's digest is computed, so the weaker ones cost nothing when writing
+// content; they are still reported by Algorithms, Expected and similar methods, but Sum and
+// CheckAlgorithm return an error for them.
+func StrongestOnly() Option {
+	return func(c *Checker) {
+		c.mode = strongestOnly
+	}
+}
+
+// DiagnoseMismatches returns an Option that makes the errors returned by Check report how many
+// leading bytes of the digest matched when there was only a single expected value, which can help
+// to tell truncated or slightly corrupted content apart from entirely different content.
+// Since the comparison isn't constant-time and the message reveals part of the expected digest,
+// this should only be used for content that you trust.
+func DiagnoseMismatches() Option {
+	return func(c *Checker) {
+		c.diagnose = true
+	}
+}
+
+// TerseErrors returns an Option that makes the errors returned by Check describe digests in base64
+// only, rather than in both base64 and hex. This is more compact for logging.
+func TerseErrors() Option {
+	return func(c *Checker) {
+		c.terse = true
+	}
+}
+
+// SkipUnknown returns an Option that makes the Checker ignore entries in the SRI string whose
+// algorithm it doesn't support, rather than failing. This is what the SRI spec recommends, so that
+// newer algorithms can be introduced without breaking older clients.
+// Creating the Checker still fails if none of the algorithms in the string are supported.
+func SkipUnknown() Option {
+	return func(c *Checker) {
+		c.lenient = true
+	}
+}
+
+// DefaultParallelThreshold is a reasonable write size above which to hash in parallel; see WithParallelHashing.
+const DefaultParallelThreshold = 64 * 1024
+
+// WithParallelHashing returns an Option that makes the Checker compute each of its hashes in a
+// separate goroutine for writes of at least the given number of bytes, which can speed up verifying
+// large content against multiple algorithms on a multi-core machine. Smaller writes are hashed
+// sequentially since the overhead of coordinating the goroutines would outweigh any benefit.
+// It has no effect if only one algorithm is in use.
+//
+// By default all hashes are written sequentially.
+func WithParallelHashing(threshold int) Option {
+	return func(c *Checker) {
+		c.parallel = threshold
+	}
+}
+
+// AnyMatch returns an Option that makes the Checker accept the content if any of the algorithms
+// present in the SRI string has a matching value, even if others (including stronger ones) don't.
+//
+// This is considerably weaker than the other modes: the content is only as trustworthy as the
+// weakest algorithm present, and an attacker who can alter the SRI string can add an entry that
+// matches whatever they like. Only use it when the SRI string comes from a trusted source.
+func AnyMatch() Option {
+	return func(c *Checker) {
+		c.mode = anyMatch
+	}
+}
+
+// AlsoCompute returns an Option that makes the Checker compute the given algorithms as well as those
+// in the SRI string, so their digests can be retrieved afterwards with Sum. They don't take part in
+// Check. Creating the Checker fails if any of them aren't supported.
+func AlsoCompute(algorithms ...string) Option {
+	return func(c *Checker) {
+		for _, name := range algorithms {
+			c.extra = append(c.extra, strings.ToLower(name))
+		}
+	}
+}
+
+// WithEncoding returns an Option that makes the Checker decode digests in the SRI string using only
+// the given base64 encoding, rather than trying hex and each of the standard base64 variants in turn.
+// Expected and ExpectedOK also return digests in this encoding; everything else (e.g. String and the
+// results of Check) still uses standard base64.
+func WithEncoding(enc *base64.Encoding) Option {
+	return func(c *Checker) {
+		c.encoding = enc
+	}
+}
+
+// OnResult returns an Option that makes the Checker call the given function for each algorithm it
+// checks during Check (or CheckDetailed), whether it matched or not, with the base64-encoded digest
+// that was computed. This is useful for emitting per-algorithm metrics.
+func OnResult(f func(algorithm string, matched bool, computed string)) Option {
+	return func(c *Checker) {
+		c.onResult = f
+	}
+}
+
+// WithPriority returns an Option that sets the order of preference of algorithms, strongest first,
+// which is used to decide which is the strongest (e.g. for StrongestOnly and Strongest) and to order them.
+// Any algorithms not listed rank below all of those that are (and among themselves, alphabetically).
+// The default is sha512, sha384, sha256, sha1.
+func WithPriority(priority ...string) Option {
+	return func(c *Checker) {
+		c.priority = make([]string, len(priority))
+		for i, name := range priority {
+			c.priority[i] = strings.ToLower(name)
+		}
+	}
+}
+
+// WithLimit returns an Option that limits the Checker to accepting at most max bytes of content.
+// Once the limit is exceeded, Write and Check both return ErrLimitExceeded.
+// A max of zero or less means there is no limit, not that no content is allowed.
+func WithLimit(max int64) Option {
+	return func(c *Checker) {
+		c.limit = max
+	}
+}
+
+// RequireStrength returns an Option that makes creating the Checker fail unless the SRI string
+// contains at least one algorithm that is as strong as the given one (e.g. sha256).
+// Creating the Checker also fails if the given algorithm isn't in its priority order (see WithPriority),
+// since its strength couldn't be compared to anything.
+func RequireStrength(min string) Option {
+	return func(c *Checker) {
+		c.minimum = strings.ToLower(min)
+	}
+}
+
 // NewChecker creates a new Checker from the given string.
 // It supports SHA256, SHA384 and SHA512 (although will only calculate those needed for the input).
 // Use NewCheckerForHashes if you need support for additional hash types.
+//
+// The returned Checker requires every algorithm present to match (see AllMustMatch); see
+// NewCheckerStrongest for the behaviour described by the SRI spec.
+//
+// Entries can be separated by any Unicode whitespace (including non-breaking spaces), zero-width
+// spaces, byte order marks, commas or semicolons.
 func NewChecker(sri string) (*Checker, error) {
-	return NewCheckerForHashes(sri, map[string]HashFunc{
-		"sha256": sha256.New,
-		"sha384": sha512.New384,
-		"sha512": sha512.New,
-	})
+	return NewCheckerForHashes(sri, defaultHashes())
+}
+
+// NewCheckerAllMustMatch is identical to NewChecker, but makes it explicit that every algorithm
+// present must match.
+func NewCheckerAllMustMatch(sri string) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), AllMustMatch())
+}
+
+// NewCheckerStrongest is like NewChecker but only checks the strongest algorithm present in the
+// SRI string (in the order sha512 > sha384 > sha256), ignoring any weaker ones.
+func NewCheckerStrongest(sri string) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), StrongestOnly())
+}
+
+// NewCheckerAnyMatch is like NewChecker but accepts the content if any algorithm present in the
+// SRI string matches. See AnyMatch for the security implications of this.
+func NewCheckerAnyMatch(sri string) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), AnyMatch())
+}
+
+// NewCheckerOptional is like NewChecker but treats an SRI string with no entries (e.g. the empty
+// string) as meaning that no verification is required; in that case it returns a Checker that
+// accepts writes as normal but whose Check always succeeds. Invalid strings are still rejected.
+func NewCheckerOptional(sri string) (*Checker, error) {
+	c, err := NewChecker(sri)
+	if errors.Is(err, ErrEmpty) {
+		c = newChecker(defaultHashes())
+		c.Reset()
+		return c, nil
+	}
+	return c, err
+}
+
+// NewCheckerWithLimit is like NewChecker but limits the amount of content that can be written to it,
+// which is useful when verifying untrusted input of a known size. See WithLimit for more details.
+func NewCheckerWithLimit(sri string, max int64) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), WithLimit(max))
+}
+
+// NewCheckerRequireStrong is like NewChecker but fails if the SRI string doesn't contain at least
+// one algorithm that is sha256 or stronger. Use RequireStrength for a different minimum.
+func NewCheckerRequireStrong(sri string) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), RequireStrength(AlgoSHA256))
+}
+
+// NewCheckerLenient is like NewChecker but ignores any entries whose algorithm isn't supported,
+// as long as at least one is. See SkipUnknown for more details.
+func NewCheckerLenient(sri string) (*Checker, error) {
+	return NewCheckerForHashes(sri, defaultHashes(), SkipUnknown())
+}
+
+// Validate checks that the given SRI string is valid, without creating a Checker.
+// It rejects exactly the same inputs that NewChecker does, but is cheaper since it never needs
+// to create any hashes.
+func Validate(sri string) error {
+	c := newChecker(defaultHashes())
+	c.sizes = defaultSizes
+	return c.parse(sri)
+}
+
+// defaultSizes are the digest sizes of each of the default hashes.
+var defaultSizes = map[string]int{
+	AlgoSHA256: sha256.Size,
+	AlgoSHA384: sha512.Size384,
+	AlgoSHA512: sha512.Size,
+}
+
+// defaultHashes returns the set of hashes that NewChecker supports.
+func defaultHashes() map[string]HashFunc {
+	return map[string]HashFunc{
+		AlgoSHA256: sha256.New,
+		AlgoSHA384: sha512.New384,
+		AlgoSHA512: sha512.New,
+	}
+}
+
+// DefaultAlgorithms returns the names of the algorithms that NewChecker supports, strongest first.
+func DefaultAlgorithms() []string {
+	return newChecker(defaultHashes()).SupportedAlgorithms()
 }
 
 // NewCheckerWithSHA1 is like NewChecker but adds SHA1 as an optional hash type.
 // This is generally useful only for compatibility and is *not* recommended by the standard, so use
 // at your own risk.
 func NewCheckerWithSHA1(sri string) (*Checker, error) {
-	return NewCheckerForHashes(sri, map[string]HashFunc{
-		"sha1":   sha1.New,
-		"sha256": sha256.New,
-		"sha384": sha512.New384,
-		"sha512": sha512.New,
+	return NewCheckerWithExtra(sri, map[string]HashFunc{
+		AlgoSHA1: sha1.New,
+	})
+}
+
+// NewCheckerWithSHA3 is like NewChecker but adds the SHA3 family as optional hash types,
+// named sha3-256, sha3-384 and sha3-512.
+// These are not part of the SRI standard but are used by some other tooling.
+func NewCheckerWithSHA3(sri string) (*Checker, error) {
+	return NewCheckerWithExtra(sri, map[string]HashFunc{
+		AlgoSHA3256: sha3.New256,
+		AlgoSHA3384: sha3.New384,
+		AlgoSHA3512: sha3.New512,
+	})
+}
+
+// NewCheckerWithTruncatedSHA512 is like NewChecker but adds the truncated SHA-512 variants as
+// optional hash types, named sha512_256 and sha512_224.
+// These are not part of the SRI standard either.
+func NewCheckerWithTruncatedSHA512(sri string) (*Checker, error) {
+	return NewCheckerWithExtra(sri, map[string]HashFunc{
+		AlgoSHA512_256: sha512.New512_256,
+		AlgoSHA512_224: sha512.New512_224,
+	})
+}
+
+// NewCheckerWithBLAKE2 is like NewChecker but adds BLAKE2b and BLAKE2s as optional hash types,
+// named blake2b-256, blake2b-384, blake2b-512 and blake2s-256.
+// Like SHA3, these are not part of the SRI standard but are common in content-addressable storage.
+func NewCheckerWithBLAKE2(sri string) (*Checker, error) {
+	return NewCheckerWithExtra(sri, map[string]HashFunc{
+		AlgoBLAKE2b256: unkeyed(blake2b.New256),
+		AlgoBLAKE2b384: unkeyed(blake2b.New384),
+		AlgoBLAKE2b512: unkeyed(blake2b.New512),
+		AlgoBLAKE2s256: unkeyed(blake2s.New256),
 	})
 }
 
+// NewCheckerWithExtra is like NewChecker but adds the given hashes to the default set.
+// If any of them have the same name as a default one, they replace it.
+// Use NewCheckerForHashes if you want full control over the set of hashes.
+func NewCheckerWithExtra(sri string, extra map[string]HashFunc, options ...Option) (*Checker, error) {
+	hashes := defaultHashes()
+	for name, f := range extra {
+		hashes[strings.ToLower(name)] = f
+	}
+	return NewCheckerForHashes(sri, hashes, options...)
+}
+
+// unkeyed adapts the constructor of a keyed hash into a HashFunc that creates it without a key.
+func unkeyed(f func(key []byte) (hash.Hash, error)) HashFunc {
+	return func() hash.Hash {
+		h, err := f(nil)
+		if err != nil {
+			// This shouldn't happen; these constructors only fail if the key is too long.
+			panic(err)
+		}
+		return h
+	}
+}
+
 // NewCheckerForHashes creates a new Checker from the given string and set of hashes.
 // It does not add any hashes by default, although will still only calculate those required by the SRI string given.
-func NewCheckerForHashes(sri string, hashes map[string]HashFunc) (*Checker, error) {
-	c := &Checker{
-		expected: map[string][]string{},
-		hashes:   map[string]hash.Hash{},
-	}
-	writers := []io.Writer{}
-	for _, field := range strings.Fields(sri) {
-		idx := strings.IndexRune(field, '-')
-		if idx == -1 {
-			return nil, fmt.Errorf("Invalid subresource integrity substring: %s", field)
-		}
-		if h, err := c.addHash(field[:idx], field[idx+1:], hashes); err != nil {
-			return nil, err
-		} else if h != nil {
-			writers = append(writers, h)
+// Algorithm names are matched case-insensitively, both in the SRI string and the given set of hashes.
+// Any options given are applied to the Checker before the SRI string is parsed.
+func NewCheckerForHashes(sri string, hashes map[string]HashFunc, options ...Option) (*Checker, error) {
+	c := newChecker(lowerKeys(hashes))
+	for _, option := range options {
+		option(c)
+	}
+	if err := c.parse(sri); err != nil {
+		return nil, err
+	} else if err := c.init(sri); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// A Pair is a single algorithm and digest, as would appear in an entry in an SRI string.
+type Pair struct {
+	// Algorithm is the name of the hash algorithm, e.g. sha256.
+	Algorithm string
+	// Digest is the expected digest, encoded in any of the forms accepted in an SRI string.
+	Digest string
+}
+
+// NewCheckerFromPairs is like NewCheckerForHashes but takes the expected values as already-separated
+// algorithm / digest pairs instead of an SRI string. The digests are validated in the same way.
+// If hashes is nil then the same set as NewChecker is supported.
+func NewCheckerFromPairs(pairs []Pair, hashes map[string]HashFunc, options ...Option) (*Checker, error) {
+	if hashes == nil {
+		hashes = defaultHashes()
+	}
+	c := newChecker(lowerKeys(hashes))
+	for _, option := range options {
+		option(c)
+	}
+	var skipped error
+	for i, pair := range pairs {
+		if err := c.addHash(strings.ToLower(pair.Algorithm), pair.Digest); err != nil {
+			if c.lenient && errors.Is(err, ErrUnknownAlgorithm) {
+				if skipped == nil {
+					skipped = &ParseError{Index: i, Token: pair.Algorithm + "-" + pair.Digest, Err: err}
+				}
+				continue
+			}
+			return nil, &ParseError{Index: i, Token: pair.Algorithm + "-" + pair.Digest, Err: err}
 		}
 	}
-	if len(writers) == 0 {
-		return nil, fmt.Errorf("Invalid subresource integrity string (empty?): %s", sri)
-	} else if len(writers) == 1 {
-		c.w = writers[0]
-	} else {
-		c.w = io.MultiWriter(writers...)
+	if len(c.expected) == 0 {
+		if skipped != nil {
+			return nil, skipped
+		}
+		return nil, ErrEmpty
+	} else if err := c.init(c.String()); err != nil {
+		return nil, err
 	}
 	return c, nil
 }
 
-// addHash adds a new hash to the checker.
-func (c *Checker) addHash(name, value string, hashes map[string]HashFunc) (hash.Hash, error) {
-	if h, present := c.hashes[name]; present {
-		if err := c.validateHash(h, name, value); err != nil {
-			return nil, err
+// init completes the setup of a Checker once its expected values have been added.
+// The given SRI string is only used for error messages.
+func (c *Checker) init(sri string) error {
+	if c.minimum != "" && !c.ranked(c.minimum) {
+		return fmt.Errorf("Cannot require strength %s since it isn't a ranked algorithm", c.minimum)
+	} else if c.minimum != "" && c.rank(c.Algorithms()[0]) > c.rank(c.minimum) {
+		return fmt.Errorf("Subresource integrity string does not contain any algorithm at least as strong as %s: %s", c.minimum, sri)
+	}
+	c.pruneWeaker()
+	for _, name := range c.extra {
+		f, present := c.supported[name]
+		if !present {
+			return fmt.Errorf("%w %s", ErrUnknownAlgorithm, name)
+		}
+		c.funcs[name] = f
+	}
+	c.Reset()
+	return nil
+}
+
+// pruneWeaker stops computing all but the strongest algorithm if this Checker only checks that one,
+// since the others would never be looked at. Any extra algorithms from AlsoCompute are kept.
+// The expected values for the weaker algorithms are unaffected.
+func (c *Checker) pruneWeaker() {
+	if c.mode != strongestOnly {
+		return
+	}
+	keep := map[string]bool{c.Algorithms()[0]: true}
+	for _, name := range c.extra {
+		keep[name] = true
+	}
+	for name := range c.funcs {
+		if !keep[name] {
+			delete(c.funcs, name)
+			delete(c.hashes, name)
+		}
+	}
+}
+
+// newChecker creates a new Checker with no expected hashes that supports the given set of hashes.
+func newChecker(hashes map[string]HashFunc) *Checker {
+	return &Checker{
+		expected:  map[string][][]byte{},
+		hashes:    map[string]hash.Hash{},
+		funcs:     map[string]HashFunc{},
+		supported: hashes,
+		options:   map[string][]string{},
+	}
+}
+
+// parse parses the given SRI string and adds all of its hashes to this Checker.
+// Entries can be separated by any Unicode whitespace, commas or semicolons (see isSeparator).
+func (c *Checker) parse(sri string) error {
+	var skipped error
+	for i, field := range strings.FieldsFunc(sri, isSeparator) {
+		if len(field) > maxEntryLength {
+			return &ParseError{Index: i, Token: field[:maxEntryLength] + "...", Err: fmt.Errorf("%w; entry is longer than %d bytes", ErrMalformedEntry, maxEntryLength)}
+		}
+		name, value, ok := splitField(field, c.supported)
+		if !ok {
+			return &ParseError{Index: i, Token: field, Err: ErrMalformedEntry}
+		}
+		value, opts := splitOptions(value)
+		if err := c.addHash(name, value); err != nil {
+			if c.lenient && errors.Is(err, ErrUnknownAlgorithm) {
+				if skipped == nil {
+					skipped = &ParseError{Index: i, Token: field, Err: err}
+				}
+				continue
+			}
+			return &ParseError{Index: i, Token: field, Err: err}
+		}
+		if len(opts) != 0 {
+			c.options[name] = append(c.options[name], opts...)
+		}
+	}
+	if len(c.expected) == 0 {
+		if skipped != nil {
+			return skipped
 		}
-		c.expected[name] = append(c.expected[name], value)
-		return nil, nil
+		return ErrEmpty
 	}
-	hash, present := hashes[name]
+	return nil
+}
+
+// Add parses the given SRI string and adds its hashes to the set that this Checker accepts.
+// Any values that are already present are ignored. It accepts the same algorithms that the
+// Checker was originally created with, and rejects invalid input in the same way.
+// If it returns an error, the Checker is unchanged.
+//
+// It should be called before any data is written, since any algorithms that weren't already
+// present won't have seen earlier writes.
+func (c *Checker) Add(sri string) error {
+	n := newChecker(c.supported)
+	n.lenient = c.lenient
+	n.encoding = c.encoding
+	if err := n.parse(sri); err != nil {
+		return err
+	}
+	for _, name := range n.Algorithms() {
+		if _, present := c.hashes[name]; !present {
+			c.funcs[name] = n.funcs[name]
+			c.hashes[name] = c.funcs[name]()
+		}
+		for _, value := range n.expected[name] {
+			if !containsExact(c.expected[name], value) {
+				c.expected[name] = append(c.expected[name], value)
+			}
+		}
+		if opts := n.options[name]; len(opts) != 0 {
+			c.options[name] = append(c.options[name], opts...)
+		}
+	}
+	c.pruneWeaker()
+	c.updateWriter()
+	return nil
+}
+
+// isSeparator returns true if the given rune separates entries in an SRI string.
+// The spec only permits ASCII whitespace, but we also accept:
+//   - any other Unicode whitespace (e.g. non-breaking spaces), which creeps in when copying from documents
+//   - zero-width spaces and byte order marks, which are similarly invisible clipboard noise
+//   - commas and semicolons, which appear in some serialised forms.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == '\u200b' || r == '\ufeff' || r == ',' || r == ';'
+}
+
+// splitField splits a single field of an SRI string into its (lowercased) algorithm name and value.
+// Since some algorithm names contain a '-' (e.g. sha3-256) it chooses the longest prefix that
+// names a known hash; if there are none it splits on the first '-'.
+//
+// Some non-conforming tools write names like SHA-256 instead of sha256; unless a hash with that exact
+// name is known, those are normalised to the standard form.
+func splitField(field string, hashes map[string]HashFunc) (string, string, bool) {
+	idx := strings.IndexRune(field, '-')
+	if idx == -1 {
+		return "", "", false
+	}
+	longest := -1
+	for i := idx + 1; i < len(field); i++ {
+		if field[i] == '-' {
+			if _, present := hashes[strings.ToLower(field[:i])]; present {
+				longest = i
+			}
+		}
+	}
+	if longest != -1 {
+		return strings.ToLower(field[:longest]), field[longest+1:], true
+	}
+	if name := strings.ToLower(field[:idx]); name == "sha" {
+		if end := strings.IndexRune(field[idx+1:], '-'); end > 0 && isDigits(field[idx+1:idx+1+end]) {
+			return name + field[idx+1:idx+1+end], field[idx+end+2:], true
+		}
+	}
+	return strings.ToLower(field[:idx]), field[idx+1:], true
+}
+
+// isDigits returns true if the given string consists only of ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// splitOptions splits any options (which follow a '?') from the given value.
+// The spec doesn't define any options yet, but requires that they are tolerated.
+func splitOptions(value string) (string, []string) {
+	parts := strings.Split(value, "?")
+	return parts[0], parts[1:]
+}
+
+// lowerKeys returns a copy of the given set of hashes with all the keys lowercased.
+func lowerKeys(hashes map[string]HashFunc) map[string]HashFunc {
+	ret := make(map[string]HashFunc, len(hashes))
+	for name, f := range hashes {
+		ret[strings.ToLower(name)] = f
+	}
+	return ret
+}
+
+// addHash adds a new expected value to the checker.
+// If the value is already expected for this algorithm it is not added again.
+// It doesn't create any hash state; that is done later by Reset.
+func (c *Checker) addHash(name, value string) error {
+	f, present := c.supported[name]
 	if !present {
-		return nil, fmt.Errorf("Unknown hash type %s", name)
+		return fmt.Errorf("%w %s", ErrUnknownAlgorithm, name)
 	}
-	h := hash()
-	if err := c.validateHash(h, name, value); err != nil {
-		return nil, err
+	decoded, err := c.validateHash(c.hashSize(name, f), name, value)
+	if err != nil {
+		return err
+	}
+	if !containsExact(c.expected[name], decoded) {
+		c.expected[name] = append(c.expected[name], decoded)
 	}
-	c.expected[name] = []string{value}
-	c.hashes[name] = h
-	return h, nil
+	c.funcs[name] = f
+	return nil
+}
+
+// hashSize returns the size of the digest for the given algorithm.
+func (c *Checker) hashSize(name string, f HashFunc) int {
+	if size, present := c.sizes[name]; present {
+		return size
+	}
+	return f().Size()
+}
+
+// updateWriter updates the writer used to write to all of this Checker's hashes.
+// This includes any extra algorithms being computed as well as those in the SRI string.
+func (c *Checker) updateWriter() {
+	algorithms := make([]string, 0, len(c.funcs))
+	for name := range c.funcs {
+		algorithms = append(algorithms, name)
+	}
+	c.sortAlgorithms(algorithms)
+	if len(algorithms) == 1 {
+		c.w = c.hashes[algorithms[0]]
+		return
+	}
+	if c.parallel > 0 {
+		hashes := make([]hash.Hash, len(algorithms))
+		for i, name := range algorithms {
+			hashes[i] = c.hashes[name]
+		}
+		c.w = &parallelWriter{hashes: hashes, threshold: c.parallel}
+		return
+	}
+	writers := make([]io.Writer, len(algorithms))
+	for i, name := range algorithms {
+		writers[i] = c.hashes[name]
+	}
+	c.w = io.MultiWriter(writers...)
 }
 
 // validateHash returns an error if the given string is not valid for a particular hash.
-func (c *Checker) validateHash(h hash.Hash, name, value string) error {
-	decoded, err := base64.StdEncoding.DecodeString(value)
+// On success it returns the decoded value.
+// Values are normally base64 encoded, but hex is accepted too if the value is the right length for it.
+func (c *Checker) validateHash(size int, name, value string) ([]byte, error) {
+	if c.encoding != nil {
+		return c.validateHashStrict(size, name, value)
+	}
+	if len(value) == hex.EncodedLen(size) {
+		if decoded, err := hex.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+	// Check the value could plausibly be the right length before decoding it, so we don't
+	// allocate and decode arbitrarily large strings only to reject them afterwards.
+	if i := strings.IndexFunc(value, isNotBase64); i != -1 {
+		return nil, fmt.Errorf("%w: illegal base64 data at input byte %d", ErrInvalidEncoding, i)
+	} else if len(value) != base64.StdEncoding.EncodedLen(size) && len(value) != base64.RawStdEncoding.EncodedLen(size) {
+		return nil, c.wrongLength(size, name, value, base64.RawStdEncoding.DecodedLen(len(strings.TrimRight(value, "="))))
+	}
+	decoded, err := decodeBase64(value)
 	if err != nil {
-		return fmt.Errorf("Invalid base64 string: %s", err)
-	} else if len(decoded) != h.Size() {
-		return fmt.Errorf("Value %s is not valid for hash type %s; should be %d bytes, was %d", value, name, h.Size(), len(decoded))
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncoding, err)
+	} else if len(decoded) != size {
+		return nil, c.wrongLength(size, name, value, len(decoded))
 	}
-	return nil
+	return decoded, nil
+}
+
+// validateHashStrict is like validateHash but only accepts values in the Checker's chosen encoding.
+func (c *Checker) validateHashStrict(size int, name, value string) ([]byte, error) {
+	if len(value) != c.encoding.EncodedLen(size) {
+		return nil, c.wrongLength(size, name, value, c.encoding.DecodedLen(len(value)))
+	}
+	decoded, err := c.encoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEncoding, err)
+	} else if len(decoded) != size {
+		return nil, c.wrongLength(size, name, value, len(decoded))
+	}
+	return decoded, nil
+}
+
+// wrongLength returns an error describing a value that decodes to the wrong number of bytes for its hash.
+func (c *Checker) wrongLength(size int, name, value string, decodedSize int) error {
+	// Most people will be pasting base64 strings, so the length of those is the most useful thing to tell them.
+	msg := fmt.Sprintf("value %s is not valid for hash type %s; expected %d base64 characters (%d bytes), was %d characters (%d bytes)", value, name, base64.StdEncoding.EncodedLen(size), size, len(value), decodedSize)
+	if likely := c.algorithmsOfSize(decodedSize); len(likely) != 0 {
+		msg += fmt.Sprintf(" (it looks like a %s digest)", strings.Join(likely, " or "))
+	}
+	return fmt.Errorf("%w: %s", ErrWrongLength, msg)
+}
+
+// isNotBase64 returns true if the given rune can't appear in any of the base64 encodings we accept.
+func isNotBase64(r rune) bool {
+	return !((r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '+' || r == '/' || r == '-' || r == '_' || r == '=')
+}
+
+// algorithmsOfSize returns the names of all supported algorithms whose digests are the given size, strongest first.
+// This is only used to give more helpful error messages so needn't be especially efficient.
+func (c *Checker) algorithmsOfSize(size int) []string {
+	var ret []string
+	for name, f := range c.supported {
+		if f().Size() == size {
+			ret = append(ret, name)
+		}
+	}
+	c.sortAlgorithms(ret)
+	return ret
+}
+
+// decodeBase64 decodes a base64 string. It prefers standard encoding but falls back to unpadded
+// and URL-safe variants since some tooling produces those instead.
+func decodeBase64(value string) ([]byte, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err == nil {
+		return decoded, nil
+	}
+	for _, enc := range []*base64.Encoding{base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if decoded, err := enc.DecodeString(value); err == nil {
+			return decoded, nil
+		}
+	}
+	return nil, err
 }
 
 // Write implements the io.Writer interface.
-// It never returns an error.
+// It never returns an error unless the Checker was created with a limit which this write exceeds,
+// in which case it writes as much as it can and returns ErrLimitExceeded.
 func (c *Checker) Write(b []byte) (int, error) {
-	return c.w.Write(b)
+	if c.closed {
+		return 0, ErrClosed
+	}
+	if c.limit > 0 && c.written+int64(len(b)) > c.limit {
+		c.exceeded = true
+		n, _ := c.w.Write(b[:c.limit-c.written])
+		c.written += int64(n)
+		return n, ErrLimitExceeded
+	}
+	n, err := c.w.Write(b)
+	c.written += int64(n)
+	return n, err
+}
+
+// WriteString implements the io.StringWriter interface.
+// It copies the string through a buffer that is reused between calls, which avoids allocating a new
+// []byte each time (the standard library hashes don't implement io.StringWriter themselves).
+func (c *Checker) WriteString(s string) (int, error) {
+	if c.closed {
+		return 0, ErrClosed
+	}
+	if len(c.buf) < len(s) && len(c.buf) < copyBufferSize {
+		if len(s) < copyBufferSize {
+			c.buf = make([]byte, len(s))
+		} else {
+			c.buf = make([]byte, copyBufferSize)
+		}
+	}
+	written := 0
+	for len(s) > 0 {
+		n := copy(c.buf, s)
+		m, err := c.Write(c.buf[:n])
+		written += m
+		if err != nil {
+			return written, err
+		}
+		s = s[n:]
+	}
+	return written, nil
+}
+
+// ReadFrom implements the io.ReaderFrom interface, which lets io.Copy write to the Checker without
+// allocating a buffer of its own. It reads from r until EOF, hashing the content in the order it is
+// read exactly as if it had been passed to Write, and returns the number of bytes consumed.
+// The buffer it reads into is reused between calls (and shared with WriteString).
+func (c *Checker) ReadFrom(r io.Reader) (int64, error) {
+	if c.closed {
+		return 0, ErrClosed
+	}
+	if len(c.buf) < copyBufferSize {
+		c.buf = make([]byte, copyBufferSize)
+	}
+	var total int64
+	for {
+		n, err := r.Read(c.buf)
+		if n > 0 {
+			written, werr := c.Write(c.buf[:n])
+			total += int64(written)
+			if werr != nil {
+				return total, werr
+			}
+		}
+		if err == io.EOF {
+			return total, nil
+		} else if err != nil {
+			return total, err
+		}
+	}
+}
+
+// Close releases any buffers held by the Checker. Afterwards, writing to it or checking it returns
+// ErrClosed; it can't be reused, even by calling Reset. Methods that only describe the expected
+// values still work. It is safe to call more than once, and always returns nil.
+func (c *Checker) Close() error {
+	c.closed = true
+	c.buf = nil
+	return nil
+}
+
+// BytesWritten returns the total number of bytes written to this Checker
+// (since it was created or last Reset).
+func (c *Checker) BytesWritten() int64 {
+	return c.written
+}
+
+// Verify reads all of the given reader into the Checker and then checks it.
+// It returns an error if reading fails or if the content doesn't match.
+func (c *Checker) Verify(r io.Reader) error {
+	if _, err := io.Copy(c, r); err != nil {
+		return err
+	}
+	return c.Check()
+}
+
+// VerifyContext is like Verify but stops reading and returns the context's error if it is
+// cancelled while reading.
+func (c *Checker) VerifyContext(ctx context.Context, r io.Reader) error {
+	buf := make([]byte, copyBufferSize)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, err := r.Read(buf)
+		if _, werr := c.Write(buf[:n]); werr != nil {
+			return werr
+		}
+		if err == io.EOF {
+			return c.Check()
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// Sum returns the digest of the data written so far for the given algorithm, which must be one
+// that this Checker is computing. It doesn't compare it against the expected values.
+func (c *Checker) Sum(name string) ([]byte, error) {
+	h, present := c.hashes[strings.ToLower(name)]
+	if !present {
+		return nil, fmt.Errorf("Hash type %s is not being computed", name)
+	}
+	return h.Sum(nil), nil
+}
+
+// SumBase64 is like Sum but returns the digest base64-encoded.
+func (c *Checker) SumBase64(name string) (string, error) {
+	sum, err := c.Sum(name)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(sum), nil
+}
+
+// Reset resets the Checker to its initial state, discarding any data written so far.
+// It retains the expected values so it can then be reused to check another resource against
+// the same SRI string.
+func (c *Checker) Reset() {
+	for name, f := range c.funcs {
+		c.hashes[name] = f()
+	}
+	c.matched = nil
+	c.verified = ""
+	c.written = 0
+	c.exceeded = false
+	c.updateWriter()
+}
+
+// A Result describes the outcome of checking a resource against the expected hashes.
+type Result struct {
+	// Algorithms contains the result for each algorithm that was checked, strongest first.
+	Algorithms []AlgorithmResult
+}
+
+// An AlgorithmResult describes the outcome of checking a single hash algorithm.
+type AlgorithmResult struct {
+	// Algorithm is the name of the hash algorithm.
+	Algorithm string
+	// Computed is the base64-encoded digest that was calculated from the content.
+	Computed string
+	// ComputedHex is the same digest, but hex-encoded.
+	ComputedHex string
+	// Expected are the base64-encoded digests that were acceptable for this algorithm.
+	Expected []string
+	// Matched is true if the computed digest was one of the expected ones.
+	Matched bool
+}
+
+// Clone returns a copy of this Checker, including the state of all the data written to it so far.
+// Subsequent writes to either one don't affect the other.
+// It returns an error if any of the underlying hashes don't implement encoding.BinaryMarshaler
+// and encoding.BinaryUnmarshaler (all the standard library ones do).
+func (c *Checker) Clone() (*Checker, error) {
+	n := c.copy()
+	for name, h := range c.hashes {
+		state, err := marshalHash(name, h)
+		if err != nil {
+			return nil, err
+		} else if err := unmarshalHash(name, n.hashes[name], state); err != nil {
+			return nil, err
+		}
+	}
+	n.matched = c.matched
+	n.verified = c.verified
+	n.written = c.written
+	n.exceeded = c.exceeded
+	return n, nil
 }
 
 // Check checks the data read so far against the expected hashes.
-// It returns an error if it does not match or nil on success.
+// It returns an *IntegrityError if it does not match or nil on success.
+// By default every algorithm present must match; if the Checker was created with StrongestOnly
+// then only the strongest one is considered, and if it was created with AnyMatch then only one
+// needs to match.
+//
+// It doesn't change the state of the Checker's hashes, so it can be called more than once, and more
+// data can be written between calls.
 func (c *Checker) Check() error {
-	var msgs []string
-	for name, hash := range c.hashes {
-		expected := c.expected[name]
-		h := hash.Sum(nil)
-		value := base64.StdEncoding.EncodeToString(h)
-		if !contains(expected, value) {
-			hexValue := hex.EncodeToString(h)
-			msgs = append(msgs, fmt.Sprintf("violated %s integrity check; was %s, expected %s (a.k.a. was %s, expected %s)", name, value, describeExpected(expected), hexValue, describeExpected(toHex(expected))))
+	_, err := c.CheckDetailed()
+	return err
+}
+
+// CheckDetailed is like Check but also returns a Result describing the outcome for each algorithm.
+// The Result is returned whether or not the check succeeds, unless the Checker's limit was exceeded,
+// in which case it is nil since nothing was checked.
+func (c *Checker) CheckDetailed() (*Result, error) {
+	c.verified = ""
+	if c.closed {
+		return nil, ErrClosed
+	} else if c.exceeded {
+		return nil, ErrLimitExceeded
+	}
+	var failures []AlgorithmResult
+	c.matched = []string{}
+	algorithms := c.Algorithms()
+	if c.mode == strongestOnly && len(algorithms) > 1 {
+		algorithms = algorithms[:1]
+	}
+	result := &Result{Algorithms: make([]AlgorithmResult, len(algorithms))}
+	for i, name := range algorithms {
+		r := c.checkAlgorithm(name)
+		if c.onResult != nil {
+			c.onResult(name, r.Matched, r.Computed)
 		}
+		if r.Matched {
+			c.matched = append(c.matched, name)
+		} else {
+			failures = append(failures, r)
+		}
+		result.Algorithms[i] = r
+	}
+	if len(failures) != 0 && (c.mode != anyMatch || len(c.matched) == 0) {
+		return result, &IntegrityError{Failures: failures, terse: c.terse, diagnose: c.diagnose, expected: c.expected}
+	}
+	if len(c.matched) != 0 {
+		c.verified = c.matched[0]
 	}
-	if len(msgs) != 0 {
-		return fmt.Errorf("subresource integrity failed: %s", strings.Join(msgs, "; "))
+	return result, nil
+}
+
+// CheckMinStrength is like Check but additionally requires that at least one of the algorithms that
+// matched is at least as strong as the given one (according to the Checker's priority order).
+// This guards against content that is only verified by a weak algorithm.
+// It returns an error if the given algorithm isn't in the Checker's priority order (see WithPriority).
+func (c *Checker) CheckMinStrength(min string) error {
+	min = strings.ToLower(min)
+	if !c.ranked(min) {
+		return fmt.Errorf("Cannot check strength against %s since it isn't a ranked algorithm", min)
+	} else if err := c.Check(); err != nil {
+		return err
+	}
+	for _, name := range c.matched {
+		if c.rank(name) <= c.rank(min) {
+			return nil
+		}
+	}
+	return fmt.Errorf("Content was not verified by any algorithm at least as strong as %s (matched %s)", min, strings.Join(c.matched, ", "))
+}
+
+// CheckAgainst is like Check but compares the digests computed so far to the given set of acceptable
+// digests instead of those from the SRI string, which lets the set of acceptable values change
+// independently of the Checker. The set maps algorithm names to digests in any of the encodings
+// accepted in an SRI string.
+//
+// Every algorithm that is both in the set and being computed by this Checker must match (only those
+// can be compared; use AlsoCompute to compute others). It returns an error if there are none, or if
+// any of the digests in the set are invalid.
+func (c *Checker) CheckAgainst(allow map[string][]string) error {
+	if c.closed {
+		return ErrClosed
+	} else if c.exceeded {
+		return ErrLimitExceeded
+	}
+	var names []string
+	expected := map[string][][]byte{}
+	for name, values := range allow {
+		name = strings.ToLower(name)
+		h, present := c.hashes[name]
+		if !present {
+			continue
+		}
+		for _, value := range values {
+			decoded, err := c.validateHash(h.Size(), name, value)
+			if err != nil {
+				return err
+			}
+			expected[name] = append(expected[name], decoded)
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("None of the algorithms being computed are in the given set")
+	}
+	c.sortAlgorithms(names)
+	var failures []AlgorithmResult
+	for _, name := range names {
+		h := c.hashes[name].Sum(nil)
+		if !contains(expected[name], h) {
+			failures = append(failures, AlgorithmResult{
+				Algorithm:   name,
+				Computed:    base64.StdEncoding.EncodeToString(h),
+				ComputedHex: hex.EncodeToString(h),
+				Expected:    toBase64(expected[name]),
+			})
+		}
+	}
+	if len(failures) != 0 {
+		return &IntegrityError{Failures: failures, terse: c.terse, diagnose: c.diagnose, expected: expected}
 	}
 	return nil
 }
 
-func contains(haystack []string, needle string) bool {
+// CheckAlgorithm is like Check but only checks the given algorithm, ignoring any others.
+// It returns an error if that algorithm isn't present in the SRI string, or isn't being computed
+// because the Checker only checks the strongest algorithm (see StrongestOnly).
+func (c *Checker) CheckAlgorithm(name string) error {
+	name = strings.ToLower(name)
+	if c.closed {
+		return ErrClosed
+	} else if _, present := c.expected[name]; !present {
+		return fmt.Errorf("Hash type %s is not present in the subresource integrity string", name)
+	} else if _, present := c.hashes[name]; !present {
+		return fmt.Errorf("Hash type %s is not being computed", name)
+	} else if c.exceeded {
+		return ErrLimitExceeded
+	}
+	if r := c.checkAlgorithm(name); !r.Matched {
+		return &IntegrityError{Failures: []AlgorithmResult{r}, terse: c.terse, diagnose: c.diagnose, expected: c.expected}
+	}
+	return nil
+}
+
+// CheckDigest checks a digest that has already been computed elsewhere against the expected
+// values for the given algorithm, without needing any content to be written to the Checker.
+// It returns an error if that algorithm isn't present in the SRI string or the digest is the wrong
+// length for it, or an *IntegrityError if it doesn't match.
+func (c *Checker) CheckDigest(name string, digest []byte) error {
+	name = strings.ToLower(name)
+	expected, present := c.expected[name]
+	if !present {
+		return fmt.Errorf("Hash type %s is not present in the subresource integrity string", name)
+	} else if len(digest) != len(expected[0]) {
+		return fmt.Errorf("%w: %s digest should be %d bytes, was %d", ErrWrongLength, name, len(expected[0]), len(digest))
+	}
+	if r := c.result(name, digest); !r.Matched {
+		return &IntegrityError{Failures: []AlgorithmResult{r}, terse: c.terse, diagnose: c.diagnose, expected: c.expected}
+	}
+	return nil
+}
+
+// checkAlgorithm checks a single algorithm and returns the result.
+func (c *Checker) checkAlgorithm(name string) AlgorithmResult {
+	return c.result(name, c.hashes[name].Sum(nil))
+}
+
+// result compares the given digest against the expected values for an algorithm.
+func (c *Checker) result(name string, h []byte) AlgorithmResult {
+	expected := c.expected[name]
+	return AlgorithmResult{
+		Algorithm:   name,
+		Computed:    base64.StdEncoding.EncodeToString(h),
+		ComputedHex: hex.EncodeToString(h),
+		Expected:    toBase64(expected),
+		Matched:     contains(expected, h),
+	}
+}
+
+// Algorithms returns the names of all the algorithms present in the SRI string for this Checker,
+// strongest first.
+func (c *Checker) Algorithms() []string {
+	ret := make([]string, 0, len(c.expected))
+	for name := range c.expected {
+		ret = append(ret, name)
+	}
+	c.sortAlgorithms(ret)
+	return ret
+}
+
+// SupportedAlgorithms returns the names of all the algorithms this Checker was created with support
+// for, strongest first. This is a superset of Algorithms, which only includes those present in the SRI string.
+func (c *Checker) SupportedAlgorithms() []string {
+	ret := make([]string, 0, len(c.supported))
+	for name := range c.supported {
+		ret = append(ret, name)
+	}
+	c.sortAlgorithms(ret)
+	return ret
+}
+
+// sortAlgorithms sorts the given slice of algorithm names, strongest first.
+func (c *Checker) sortAlgorithms(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := c.rank(names[i]), c.rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+}
+
+// rank returns the rank of the given algorithm name; lower is stronger.
+func (c *Checker) rank(name string) int {
+	priority := c.priority
+	if priority == nil {
+		priority = defaultPriority
+	}
+	for i, p := range priority {
+		if p == name {
+			return i
+		}
+	}
+	return len(priority)
+}
+
+// ranked returns true if the given algorithm appears in this Checker's priority order.
+func (c *Checker) ranked(name string) bool {
+	if c.priority == nil {
+		return c.rank(name) < len(defaultPriority)
+	}
+	return c.rank(name) < len(c.priority)
+}
+
+// contains returns true if the given digest is present in the haystack.
+// The comparisons are done in constant time so as not to leak information about the expected digests.
+func contains(haystack [][]byte, needle []byte) bool {
+	found := 0
+	for _, straw := range haystack {
+		found |= subtle.ConstantTimeCompare(straw, needle)
+	}
+	return found == 1
+}
+
+// containsExact is like contains but is not constant-time. It should only be used for checking
+// expected values against one another, not for checking them against computed digests.
+func containsExact(haystack [][]byte, needle []byte) bool {
 	for _, straw := range haystack {
-		if straw == needle {
+		if bytes.Equal(straw, needle) {
 			return true
 		}
 	}
 	return false
 }
 
-func describeExpected(expected []string) string {
-	if len(expected) == 1 {
-		return expected[0]
+// toBase64 converts a slice of raw digests to base64-encoded strings.
+func toBase64(expected [][]byte) []string {
+	ret := make([]string, len(expected))
+	for i, e := range expected {
+		ret[i] = base64.StdEncoding.EncodeToString(e)
 	}
-	return fmt.Sprintf("one of [%s]", strings.Join(expected, ", "))
+	return ret
+}
+
+// ExpectedBytes is like Expected but returns the raw expected digests.
+// The returned slices are copies so can be freely modified.
+func (c *Checker) ExpectedBytes(name string) [][]byte {
+	expected, present := c.expected[strings.ToLower(name)]
+	if !present {
+		return nil
+	}
+	ret := make([][]byte, len(expected))
+	for i, e := range expected {
+		ret[i] = append([]byte{}, e...)
+	}
+	return ret
 }
 
-// toHex converts a slice of base64-encoded strings to hex-encoded.
-func toHex(expected []string) []string {
+// toHex converts a slice of raw digests to hex-encoded strings.
+func toHex(expected [][]byte) []string {
 	ret := make([]string, len(expected))
 	for i, e := range expected {
-		// We know these are valid because we check it in validateHash.
-		raw, _ := base64.StdEncoding.DecodeString(e)
-		ret[i] = hex.EncodeToString(raw)
+		ret[i] = hex.EncodeToString(e)
 	}
 	return ret
 }
 
 // Expected returns the expected hashes for the given hash name.
+// The name is matched case-insensitively.
 func (c *Checker) Expected(name string) []string {
-	return c.expected[name]
+	expected, present := c.expected[strings.ToLower(name)]
+	if !present {
+		return nil
+	}
+	return c.toBase64(expected)
+}
+
+// toBase64 is like the package-level toBase64 but uses the Checker's encoding if it has one.
+func (c *Checker) toBase64(expected [][]byte) []string {
+	if c.encoding == nil {
+		return toBase64(expected)
+	}
+	ret := make([]string, len(expected))
+	for i, e := range expected {
+		ret[i] = c.encoding.EncodeToString(e)
+	}
+	return ret
+}
+
+// ExpectedOK is like Expected but also returns whether the algorithm is present at all,
+// which distinguishes an algorithm that isn't in the SRI string from one with no expected values.
+func (c *Checker) ExpectedOK(name string) ([]string, bool) {
+	expected, present := c.expected[strings.ToLower(name)]
+	if !present {
+		return nil, false
+	}
+	return c.toBase64(expected), true
+}
+
+// ExpectedHex is like Expected but returns the expected hashes hex-encoded.
+func (c *Checker) ExpectedHex(name string) []string {
+	expected, present := c.expected[strings.ToLower(name)]
+	if !present {
+		return nil
+	}
+	return toHex(expected)
+}
+
+// RangeExpectedHex calls the given function with each of the expected hashes for the given hash
+// name, hex-encoded. It is like ExpectedHex but avoids building a slice for callers that only need
+// to iterate over them. It does nothing if the algorithm isn't present.
+func (c *Checker) RangeExpectedHex(name string, fn func(hex string)) {
+	for _, e := range c.expected[strings.ToLower(name)] {
+		fn(hex.EncodeToString(e))
+	}
+}
+
+// Matched returns the names of the algorithms that matched during the last call to Check, strongest first.
+// It is empty if Check has not been called yet (or not since the last call to Reset).
+// Note that if the Checker was created with StrongestOnly then only the strongest algorithm is considered.
+func (c *Checker) Matched() []string {
+	return c.matched
+}
+
+// VerifiedWith returns the name of the strongest algorithm that matched if the last call to Check
+// succeeded, for example to record in an audit log. It is the empty string if Check has not been
+// called yet (or not since the last call to Reset), or if it failed.
+func (c *Checker) VerifiedWith() string {
+	return c.verified
+}
+
+// String returns the canonical SRI string for this Checker.
+// Algorithms are ordered strongest first (sha512, sha384, sha256, sha1, then any others
+// alphabetically), and values are in the order they were given.
+func (c *Checker) String() string {
+	return c.format(c.Algorithms())
+}
+
+// Strongest returns the canonical SRI string for only the strongest algorithm in this Checker,
+// or the empty string if there are none. If there are multiple values for that algorithm, they
+// are all included.
+func (c *Checker) Strongest() string {
+	if algorithms := c.Algorithms(); len(algorithms) != 0 {
+		return c.format(algorithms[:1])
+	}
+	return ""
+}
+
+// Weakest returns the name of the weakest algorithm present in the SRI string for this Checker,
+// according to the same ordering as Strongest, or the empty string if there are none.
+func (c *Checker) Weakest() string {
+	if algorithms := c.Algorithms(); len(algorithms) != 0 {
+		return algorithms[len(algorithms)-1]
+	}
+	return ""
+}
+
+// format returns the canonical SRI string for the given algorithms in this Checker.
+func (c *Checker) format(algorithms []string) string {
+	var entries []string
+	for _, name := range algorithms {
+		for _, value := range c.expected[name] {
+			entries = append(entries, name+"-"+base64.StdEncoding.EncodeToString(value))
+		}
+	}
+	return strings.Join(entries, " ")
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+// It returns the same canonical form as String.
+func (c *Checker) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// It parses the text in the same way as NewChecker (and hence supports the same set of algorithms).
+func (c *Checker) UnmarshalText(text []byte) error {
+	checker, err := NewChecker(string(text))
+	if err != nil {
+		return err
+	}
+	*c = *checker
+	return nil
+}
+
+// Has returns true if the SRI string for this Checker contains any values for the given algorithm.
+func (c *Checker) Has(name string) bool {
+	_, present := c.expected[strings.ToLower(name)]
+	return present
+}
+
+// IsExpected returns true if the given digest is one of the expected values for the given algorithm.
+// The digest can be encoded in any of the forms accepted in an SRI string. It returns false if the
+// algorithm isn't present or the digest is invalid.
+func (c *Checker) IsExpected(name, digest string) bool {
+	name = strings.ToLower(name)
+	expected, present := c.expected[name]
+	if !present {
+		return false
+	}
+	decoded, err := c.validateHash(len(expected[0]), name, digest)
+	return err == nil && contains(expected, decoded)
+}
+
+// Equal returns true if the other Checker expects exactly the same set of digests for each
+// algorithm as this one, regardless of their order or how they were encoded.
+// Neither Checker's options nor any content written to them are taken into account.
+func (c *Checker) Equal(other *Checker) bool {
+	return other != nil && equalExpected(c.expected, other.expected)
+}
+
+// recommendedAlgorithms are the algorithms that the SRI spec requires user agents to support.
+var recommendedAlgorithms = map[string]bool{
+	AlgoSHA256: true,
+	AlgoSHA384: true,
+	AlgoSHA512: true,
+}
+
+// UsesRecommendedAlgorithmsOnly returns true if the SRI string for this Checker only contains the
+// algorithms recommended by the SRI spec (sha256, sha384 and sha512), i.e. nothing weaker like
+// sha1 or md5 and nothing non-standard like sha3-256.
+func (c *Checker) UsesRecommendedAlgorithmsOnly() bool {
+	for name := range c.expected {
+		if !recommendedAlgorithms[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// Count returns the number of distinct values the SRI string for this Checker contains for the given algorithm.
+func (c *Checker) Count(name string) int {
+	return len(c.expected[strings.ToLower(name)])
+}
+
+// Options returns any options that were given for the given algorithm in the SRI string
+// (i.e. anything following a '?' after the digest), in the order they appeared.
+// None are currently defined by the spec, but any given are retained.
+func (c *Checker) Options(name string) []string {
+	return c.options[strings.ToLower(name)]
 }
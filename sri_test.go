@@ -1,10 +1,24 @@
 package sri
 
 import (
+	"context"
 	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"hash/crc32"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestSuccess(t *testing.T) {
@@ -78,6 +92,18 @@ func TestInvalidHashName(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestInvalidHashNameLenient(t *testing.T) {
+	// sha1 isn't supported by default, but a lenient Checker ignores it in favour of sha256.
+	c, err := NewCheckerLenient(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	// It still fails if sha1 is the only entry.
+	_, err = NewCheckerLenient(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=`)
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
+
 func TestCustomHashType(t *testing.T) {
 	c, err := NewCheckerForHashes("md5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{
 		"md5": md5.New,
@@ -119,6 +145,11 @@ func TestNonsenseInput(t *testing.T) {
 func TestNoInput(t *testing.T) {
 	_, err := NewChecker(``)
 	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrEmpty))
+	_, err = NewChecker(" \n\t, ")
+	assert.True(t, errors.Is(err, ErrEmpty))
+	_, err = NewChecker("sha256-")
+	assert.False(t, errors.Is(err, ErrEmpty))
 }
 
 func TestExpected(t *testing.T) {
@@ -141,3 +172,1383 @@ sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxK
 	}, c.Expected("sha512"))
 	assert.Nil(t, c.Expected("md5"))
 }
+
+func TestStrongestMixedFailure(t *testing.T) {
+	// This is the same input as TestMixedFailure, but only SHA512 is considered so it still fails.
+	c, err := NewCheckerStrongest(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+}
+
+func TestStrongestIgnoresWeaker(t *testing.T) {
+	// The SHA256 and SHA384 values here are wrong, but SHA512 is right so it should pass.
+	c, err := NewCheckerStrongest(`
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=
+sha384-ixBUOCmT6wnGpEL5AxEsAm9EdJCBj7kF099SUkvIbtB63ydFdgNgXVj784BCcJ2k
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestStrongestCustomHashes(t *testing.T) {
+	// md5 isn't ranked so sha1 is the strongest here.
+	c, err := NewCheckerForHashes("md5-AAAAAAAAAAAAAAAAAAAAAA== sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=", map[string]HashFunc{
+		"md5":  md5.New,
+		"sha1": sha1.New,
+	}, StrongestOnly())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestReset(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a burrito"))
+	assert.Error(t, c.Check())
+	c.Reset()
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	c.Reset()
+	c.Write([]byte("I want a sandwich with extra mayonnaise"))
+	assert.Error(t, c.Check())
+}
+
+func TestContains(t *testing.T) {
+	haystack := [][]byte{{1, 2, 3}, {4, 5, 6}}
+	assert.True(t, contains(haystack, []byte{4, 5, 6}))
+	assert.False(t, contains(haystack, []byte{4, 5, 7}))
+	assert.False(t, contains(haystack, []byte{4, 5}))
+	assert.False(t, contains(nil, []byte{4, 5, 6}))
+}
+
+func TestURLEncoding(t *testing.T) {
+	// This is only valid using the URL-safe alphabet.
+	c, err := NewChecker("sha384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"}, c.Expected("sha384"))
+}
+
+func TestRawURLEncoding(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+}
+
+func TestMixedCase(t *testing.T) {
+	c, err := NewChecker(`
+SHA256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+Sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+	assert.Equal(t, []string{"4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"}, c.Expected("SHA384"))
+}
+
+func TestMixedCaseCustomHashType(t *testing.T) {
+	c, err := NewCheckerForHashes("md5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{
+		"MD5": md5.New,
+	})
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestSHA3(t *testing.T) {
+	c, err := NewCheckerWithSHA3(`sha3-256-m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8=`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8="}, c.Expected("sha3-256"))
+}
+
+func TestSHA3Multi(t *testing.T) {
+	c, err := NewCheckerWithSHA3(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha3-384-kO5ROOG6mO0/qsNNkqVNjh9/Loo5BoLecr3MjyUzpIUyqTeHiEBdd206ouwy3PB1
+SHA3-512-v4v9Yv+vWmq3GVIn2MjvQ1plBnwf7e/1ZVc8b591dpV6sC1BWpLs9JAYtQwIdywFtsljpVGeh+HZEw6n82pHuA==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestTruncatedSHA512(t *testing.T) {
+	c, err := NewCheckerWithTruncatedSHA512(`sha512_256-3S6LHhN9pwvnAL9R6QGixHOMUUb0ddDXvGqTgJNGVVc= SHA512_224-VhZueYcPNEVt1l8jrrjOvDoCutGgKdl6o6czyQ==`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{AlgoSHA512_224, AlgoSHA512_256}, c.Algorithms())
+}
+
+func TestTruncatedSHA512WrongLength(t *testing.T) {
+	// This is a sha256 digest, which is the right length for sha512_256 but not sha512_224.
+	_, err := NewCheckerWithTruncatedSHA512(`sha512_256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`)
+	assert.NoError(t, err)
+	_, err = NewCheckerWithTruncatedSHA512(`sha512_224-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`)
+	assert.True(t, errors.Is(err, ErrWrongLength))
+}
+
+func TestSHA3NotSupportedByDefault(t *testing.T) {
+	_, err := NewChecker(`sha3-256-m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8=`)
+	assert.Error(t, err)
+}
+
+func TestMatched(t *testing.T) {
+	c, err := NewChecker(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==
+`)
+	assert.NoError(t, err)
+	assert.Empty(t, c.Matched())
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+	assert.Equal(t, []string{"sha384", "sha256"}, c.Matched())
+	c.Reset()
+	assert.Empty(t, c.Matched())
+}
+
+func TestMatchedNone(t *testing.T) {
+	c, err := NewChecker("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+	assert.NotNil(t, c.Matched())
+	assert.Empty(t, c.Matched())
+}
+
+func TestCheckDetailed(t *testing.T) {
+	c, err := NewChecker(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	result, err := c.CheckDetailed()
+	assert.Error(t, err)
+	assert.Equal(t, &Result{
+		Algorithms: []AlgorithmResult{
+			{
+				Algorithm:   "sha512",
+				Computed:    "xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==",
+				ComputedHex: "c4ba581047a7e394499d7c66140092ebafac3bfd57ba8d7dd97abab886ab608e2e13b3197af236a53ca82946401553fdb6f7e12534ba62338970c73c724a1193",
+				Expected:    []string{"jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA=="},
+				Matched:     false,
+			},
+			{
+				Algorithm:   "sha256",
+				Computed:    "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+				ComputedHex: "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d",
+				Expected:    []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+				Matched:     true,
+			},
+		},
+	}, result)
+}
+
+func TestVerify(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Verify(strings.NewReader("I want a sandwich")))
+}
+
+func TestVerifyFailure(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Error(t, c.Verify(strings.NewReader("I want a burrito")))
+}
+
+func TestVerifyReadError(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Equal(t, iotest.ErrTimeout, c.Verify(iotest.TimeoutReader(strings.NewReader("I want a sandwich"))))
+}
+
+func TestString(t *testing.T) {
+	c1, err := NewChecker(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+`)
+	assert.NoError(t, err)
+	c2, err := NewChecker(`SHA384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`)
+	assert.NoError(t, err)
+	const expected = "sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="
+	assert.Equal(t, expected, c1.String())
+	assert.Equal(t, expected, c2.String())
+}
+
+func TestTextMarshalling(t *testing.T) {
+	type config struct {
+		Integrity *Checker `json:"integrity"`
+	}
+	var cfg config
+	assert.NoError(t, json.Unmarshal([]byte(`{"integrity": "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="}`), &cfg))
+	cfg.Integrity.Write([]byte("I want a sandwich"))
+	assert.NoError(t, cfg.Integrity.Check())
+	b, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"integrity":"sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}`, string(b))
+}
+
+func TestTextRoundTrip(t *testing.T) {
+	c1, err := NewChecker("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	text, err := c1.MarshalText()
+	assert.NoError(t, err)
+	c2 := &Checker{}
+	assert.NoError(t, c2.UnmarshalText(text))
+	assert.Equal(t, c1.String(), c2.String())
+	assert.Error(t, c2.UnmarshalText([]byte("wibble")))
+}
+
+func TestOptions(t *testing.T) {
+	c, err := NewChecker(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=?foo=bar
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==?wibble?wobble
+sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==?wubble
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"foo=bar"}, c.Options("sha256"))
+	assert.Equal(t, []string{"wibble", "wobble", "wubble"}, c.Options("sha512"))
+	assert.Nil(t, c.Options("sha384"))
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+}
+
+func TestAdd(t *testing.T) {
+	c, err := NewChecker("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Add("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="))
+	assert.NoError(t, c.Add("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"))
+	assert.Equal(t, []string{
+		"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=",
+		"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	}, c.Expected("sha256"))
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"sha384", "sha256"}, c.Matched())
+}
+
+func TestAddInvalid(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	// The second entry here is the wrong length; the first shouldn't get added either.
+	assert.Error(t, c.Add("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-ixBUOCmT6wnGpEL5AxEsAm9EdJCBj7kF099SUkvIbtB63ydFdgNgXVj784BCcJ2k"))
+	assert.Nil(t, c.Expected("sha384"))
+	assert.Error(t, c.Add("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU="))
+	assert.Error(t, c.Add(""))
+}
+
+func TestDuplicates(t *testing.T) {
+	// The second and fourth entries are duplicates of the first (although the fourth is differently encoded)
+	c, err := NewChecker(`
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=",
+		"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	}, c.Expected("sha256"))
+	c.Write([]byte("I want a burrito"))
+	err = c.Check()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "expected one of [49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=, y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=]")
+}
+
+func TestAlgorithms(t *testing.T) {
+	c, err := NewCheckerForHashes("md5-IdZNPlbFer1sm3bEsO3Mpw== sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= crc32-AAAAAA== sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", map[string]HashFunc{
+		"md5":    md5.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+		"crc32":  func() hash.Hash { return crc32.NewIEEE() },
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha256", "crc32", "md5"}, c.Algorithms())
+}
+
+func TestVerifyContext(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.NoError(t, c.VerifyContext(context.Background(), iotest.OneByteReader(strings.NewReader("I want a sandwich"))))
+}
+
+func TestVerifyContextCancelled(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &cancellingReader{r: strings.NewReader("I want a sandwich"), cancel: cancel, n: 5}
+	assert.Equal(t, context.Canceled, c.VerifyContext(ctx, iotest.OneByteReader(r)))
+	assert.Equal(t, 5, r.reads)
+}
+
+func TestVerifyContextLimit(t *testing.T) {
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 10)
+	assert.NoError(t, err)
+	r := strings.NewReader(strings.Repeat("I want a sandwich", 1000))
+	assert.Equal(t, ErrLimitExceeded, c.VerifyContext(context.Background(), iotest.OneByteReader(r)))
+	// It should stop reading as soon as the limit is exceeded.
+	assert.Equal(t, 17*1000-11, r.Len())
+}
+
+func TestVerifyContextClosed(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Close())
+	r := strings.NewReader("I want a sandwich")
+	assert.Equal(t, ErrClosed, c.VerifyContext(context.Background(), iotest.OneByteReader(r)))
+	assert.Equal(t, 16, r.Len())
+}
+
+// A cancellingReader cancels a context after a number of reads.
+type cancellingReader struct {
+	r      io.Reader
+	cancel context.CancelFunc
+	n      int
+	reads  int
+}
+
+func (r *cancellingReader) Read(b []byte) (int, error) {
+	r.reads++
+	if r.reads == r.n {
+		r.cancel()
+	}
+	return r.r.Read(b)
+}
+
+func TestHex(t *testing.T) {
+	c, err := NewChecker("sha256-cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d sha384-E10BAC7A24FD590FBCD040D9FCC613A1D6AC74D0532C80BFF46D579924039938D3BC333CAB4D158316BD9CC830530DE3")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+	assert.Equal(t, []string{"4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"}, c.Expected("sha384"))
+}
+
+func TestHexWrongLength(t *testing.T) {
+	// This is a valid hex-encoded sha256, but it's labelled as sha512.
+	_, err := NewChecker("sha512-cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d")
+	assert.Error(t, err)
+}
+
+func TestBLAKE2(t *testing.T) {
+	c, err := NewCheckerWithBLAKE2(`
+blake2b-256-Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs=
+blake2b-384-h7ts/J5w2Nhmwy9xCHo4nsmwJvFX3/D3Ike5EX+yhnZ6/W+ET+m62sV72TmJ32XG
+blake2b-512-PK+BYqRiUPcz/PPFOwVP+It/uyVvR+h0lkeE48w/PwrVpoCBf5B5ui8HW8pkx5UFk3i+9ylc2aq8dTpjLhqpCQ==
+blake2s-256-/xxQZDdJdWVmZgwnHYEdf9yn7rnrQIQvnhUhzP6RYYQ=
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs="}, c.Expected(AlgoBLAKE2b256))
+}
+
+func TestBLAKE2WrongLength(t *testing.T) {
+	_, err := NewCheckerWithBLAKE2(`blake2b-512-Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs=`)
+	assert.Error(t, err)
+}
+
+func TestExtra(t *testing.T) {
+	c, err := NewCheckerWithExtra("md5-IdZNPlbFer1sm3bEsO3Mpw== sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", map[string]HashFunc{
+		"MD5": md5.New,
+	})
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"sha256", "md5"}, c.Matched())
+}
+
+func TestCommaSeparated(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=, sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j,sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha384", "sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestMixedSeparators(t *testing.T) {
+	c, err := NewChecker(";sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=;; ,sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j\n;\tsha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==,")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha384", "sha256"}, c.Algorithms())
+}
+
+func TestOnlySeparators(t *testing.T) {
+	_, err := NewChecker(" , ;; ")
+	assert.Error(t, err)
+}
+
+func TestWrongAlgorithmHint(t *testing.T) {
+	// This is a sha512 digest labelled as sha256.
+	_, err := NewChecker("sha256-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "it looks like a sha512 digest")
+	// This one is the right length for several algorithms.
+	_, err = NewCheckerWithSHA3("sha384-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "it looks like a sha256 or sha3-256 digest")
+	// This doesn't match anything so we don't hint.
+	_, err = NewChecker("sha256-AAAA")
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "looks like")
+}
+
+func TestWrongLengthBase64Characters(t *testing.T) {
+	_, err := NewChecker("sha256-AAAA")
+	assert.True(t, errors.Is(err, ErrWrongLength))
+	assert.Contains(t, err.Error(), "expected 44 base64 characters (32 bytes), was 4 characters (3 bytes)")
+	// Custom hashes get the same treatment, based on their size.
+	_, err = NewCheckerForHashes("md5-AAAA", map[string]HashFunc{"md5": md5.New})
+	assert.Contains(t, err.Error(), "expected 24 base64 characters (16 bytes)")
+}
+
+func TestSum(t *testing.T) {
+	c, err := NewChecker("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+	sum, err := c.Sum("sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d", hex.EncodeToString(sum))
+	s, err := c.SumBase64("SHA256")
+	assert.NoError(t, err)
+	assert.Equal(t, "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", s)
+	_, err = c.Sum("sha512")
+	assert.Error(t, err)
+	_, err = c.SumBase64("sha512")
+	assert.Error(t, err)
+}
+
+func TestClone(t *testing.T) {
+	c1, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	c1.Write([]byte("I want a "))
+	c2, err := c1.Clone()
+	assert.NoError(t, err)
+	c1.Write([]byte("sandwich"))
+	c2.Write([]byte("burrito"))
+	assert.NoError(t, c1.Check())
+	assert.Error(t, c2.Check())
+	assert.Equal(t, c1.String(), c2.String())
+}
+
+// An unmarshalableHash hides the encoding.BinaryMarshaler implementation of the hash it wraps,
+// since embedding the interface only promotes the methods of hash.Hash.
+type unmarshalableHash struct {
+	hash.Hash
+}
+
+func newUnmarshalableSHA256() hash.Hash {
+	return unmarshalableHash{sha256.New()}
+}
+
+func TestCloneUnsupported(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", map[string]HashFunc{
+		"sha256": newUnmarshalableSHA256,
+	})
+	assert.NoError(t, err)
+	_, err = c.Clone()
+	assert.Error(t, err)
+}
+
+func TestBytesWritten(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, c.BytesWritten())
+	c.Write([]byte("I want "))
+	c.Write([]byte("a sandwich"))
+	assert.EqualValues(t, 17, c.BytesWritten())
+	c2, err := c.Clone()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 17, c2.BytesWritten())
+	c.Reset()
+	assert.EqualValues(t, 0, c.BytesWritten())
+}
+
+func TestLimit(t *testing.T) {
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 17)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Verify(strings.NewReader("I want a sandwich")))
+}
+
+func TestLimitExceeded(t *testing.T) {
+	// The first 17 bytes here would match, but we shouldn't accept it since it's too long.
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 17)
+	assert.NoError(t, err)
+	n, err := c.Write([]byte("I want a sandwich!"))
+	assert.Equal(t, ErrLimitExceeded, err)
+	assert.Equal(t, 17, n)
+	assert.EqualValues(t, 17, c.BytesWritten())
+	assert.Equal(t, ErrLimitExceeded, c.Check())
+	c.Reset()
+	assert.NoError(t, c.Verify(strings.NewReader("I want a sandwich")))
+}
+
+func TestHasAndCount(t *testing.T) {
+	c, err := NewChecker(`
+sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+`)
+	assert.NoError(t, err)
+	assert.True(t, c.Has("sha256"))
+	assert.True(t, c.Has("SHA384"))
+	assert.False(t, c.Has("sha512"))
+	assert.Equal(t, 2, c.Count("sha256"))
+	assert.Equal(t, 1, c.Count("sha384"))
+	assert.Equal(t, 0, c.Count("sha512"))
+}
+
+func TestRequireStrong(t *testing.T) {
+	_, err := NewCheckerRequireStrong("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	_, err = NewCheckerForHashes("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= md5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{
+		"sha1": sha1.New,
+		"md5":  md5.New,
+	}, RequireStrength("sha256"))
+	assert.Error(t, err)
+}
+
+func TestRequireStrengthUnranked(t *testing.T) {
+	// An algorithm that isn't ranked can't be compared, so mustn't silently disable the requirement.
+	_, err := NewCheckerForHashes("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=", map[string]HashFunc{
+		"sha1": sha1.New,
+	}, RequireStrength("SHA-384"))
+	assert.Error(t, err)
+	_, err = NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", defaultHashes(), RequireStrength("sha256"), WithPriority("sha512", "sha384"))
+	assert.Error(t, err)
+}
+
+func TestRequireStrength(t *testing.T) {
+	_, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", defaultHashes(), RequireStrength("sha384"))
+	assert.Error(t, err)
+	_, err = NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", defaultHashes(), RequireStrength("SHA384"))
+	assert.NoError(t, err)
+}
+
+func TestCheckTwice(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	err1 := c.Check()
+	err2 := c.Check()
+	assert.Error(t, err1)
+	assert.Equal(t, err1, err2)
+	assert.Equal(t, []string{"sha256"}, c.Matched())
+}
+
+func TestCheckBetweenWrites(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a "))
+	assert.Error(t, c.Check())
+	c.Write([]byte("sandwich"))
+	assert.NoError(t, c.Check())
+	assert.NoError(t, c.Check())
+}
+
+func TestAllMustMatch(t *testing.T) {
+	// The same input as TestStrongestIgnoresWeaker; this passes for the strongest but not here.
+	c, err := NewCheckerAllMustMatch(`
+sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=
+sha384-ixBUOCmT6wnGpEL5AxEsAm9EdJCBj7kF099SUkvIbtB63ydFdgNgXVj784BCcJ2k
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==
+`)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+}
+
+func TestAllMustMatchOverridesStrongest(t *testing.T) {
+	// Later options take precedence.
+	c, err := NewCheckerForHashes("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", defaultHashes(), StrongestOnly(), AllMustMatch())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+}
+
+func TestUnpadded(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0 sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+	assert.Equal(t, []string{"xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="}, c.Expected("sha512"))
+}
+
+func TestWriteString(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	n, err := c.WriteString("I want ")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, n)
+	n, err = io.WriteString(c, "a sandwich")
+	assert.NoError(t, err)
+	assert.Equal(t, 10, n)
+	assert.NoError(t, c.Check())
+	assert.EqualValues(t, 17, c.BytesWritten())
+}
+
+func TestWriteStringLarge(t *testing.T) {
+	s := strings.Repeat("I want a sandwich", 10000)
+	expected, err := Generate([]byte(s), "sha256")
+	assert.NoError(t, err)
+	c, err := NewChecker(expected)
+	assert.NoError(t, err)
+	n, err := c.WriteString(s)
+	assert.NoError(t, err)
+	assert.Equal(t, len(s), n)
+	assert.NoError(t, c.Check())
+}
+
+func TestWriteStringLimit(t *testing.T) {
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 10)
+	assert.NoError(t, err)
+	n, err := c.WriteString("I want a sandwich")
+	assert.Equal(t, ErrLimitExceeded, err)
+	assert.Equal(t, 10, n)
+}
+
+const benchmarkSRI = "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="
+
+var benchmarkString = strings.Repeat("I want a sandwich", 64)
+
+func BenchmarkWriteBytesFromString(b *testing.B) {
+	c, _ := NewChecker(benchmarkSRI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Write([]byte(benchmarkString))
+	}
+}
+
+func BenchmarkWriteString(b *testing.B) {
+	c, _ := NewChecker(benchmarkSRI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.WriteString(benchmarkString)
+	}
+}
+
+func TestStrongest(t *testing.T) {
+	c, err := NewChecker(`
+SHA256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=
+sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw
+sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", c.Strongest())
+	assert.Equal(t, "", (&Checker{}).Strongest())
+}
+
+func TestPriority(t *testing.T) {
+	// md5 and blake2b are unranked by default, so we rank them explicitly.
+	hashes := map[string]HashFunc{
+		"md5":         md5.New,
+		"sha256":      sha256.New,
+		"blake2b-256": unkeyed(blake2b.New256),
+	}
+	const sri = "md5-IdZNPlbFer1sm3bEsO3Mpw== sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= blake2b-256-Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs="
+	c, err := NewCheckerForHashes(sri, hashes, StrongestOnly(), WithPriority("BLAKE2b-256", "sha256"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"blake2b-256", "sha256", "md5"}, c.Algorithms())
+	assert.Equal(t, "blake2b-256-Qscfqv1bMmwAt0EYBPBl8boZdxgRgPLFMpUDugR6fTs=", c.Strongest())
+	// The sha256 value is wrong but it isn't considered.
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"blake2b-256"}, c.Matched())
+	// By default sha256 would be strongest.
+	c, err = NewCheckerForHashes(sri, hashes, StrongestOnly())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+}
+
+func TestExpectedBytes(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	b := c.ExpectedBytes("sha256")
+	assert.Equal(t, 1, len(b))
+	assert.Equal(t, "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d", hex.EncodeToString(b[0]))
+	// Modifying the result mustn't affect the checker.
+	b[0][0] = 0
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+	assert.Nil(t, c.ExpectedBytes("sha512"))
+}
+
+func TestExpectedOK(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	expected, ok := c.ExpectedOK("SHA256")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, expected)
+	expected, ok = c.ExpectedOK("md5")
+	assert.False(t, ok)
+	assert.Nil(t, expected)
+}
+
+func TestRangeExpectedHex(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	var hexes []string
+	c.RangeExpectedHex("SHA256", func(hex string) {
+		hexes = append(hexes, hex)
+	})
+	assert.Equal(t, c.ExpectedHex("sha256"), hexes)
+	c.RangeExpectedHex("sha512", func(hex string) {
+		t.Errorf("Unexpected call for sha512")
+	})
+}
+
+func TestValidate(t *testing.T) {
+	for _, sri := range []string{
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+		"sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==?foo",
+		"sha256-cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d",
+		"sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=",
+		"sha256-wibblewibblewibble",
+		"sha256-ixBUOCmT6wnGpEL5AxEsAm9EdJCBj7kF099SUkvIbtB63ydFdgNgXVj784BCcJ2k",
+		"wibble wibble wibble",
+		"",
+	} {
+		_, err := NewChecker(sri)
+		assert.Equal(t, err, Validate(sri), "for %s", sri)
+	}
+}
+
+func TestDefaultSizes(t *testing.T) {
+	hashes := defaultHashes()
+	assert.Equal(t, len(hashes), len(defaultSizes))
+	for name, f := range hashes {
+		assert.Equal(t, f().Size(), defaultSizes[name])
+	}
+}
+
+func TestCheckAlgorithm(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+	assert.NoError(t, c.CheckAlgorithm("sha256"))
+	assert.NoError(t, c.CheckAlgorithm("SHA256"))
+	err = c.CheckAlgorithm("sha512")
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Equal(t, "sha512", ierr.Failures[0].Algorithm)
+	err = c.CheckAlgorithm("sha384")
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &ierr))
+}
+
+func TestPathologicalInput(t *testing.T) {
+	for _, sri := range []string{
+		"-",
+		"--",
+		"sha256-",
+		"-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha256\x00-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=\x00",
+		"sha256-?",
+		"sha256-" + strings.Repeat("A", 10*1024*1024),
+		strings.Repeat("-", 10*1024*1024),
+		"\xff\xfe\xfd",
+	} {
+		c, err := NewChecker(sri)
+		assert.Error(t, err)
+		assert.Nil(t, c)
+	}
+}
+
+func TestEntryTooLong(t *testing.T) {
+	_, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=?" + strings.Repeat("A", 1024))
+	var perr *ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.True(t, errors.Is(err, ErrMalformedEntry))
+	assert.True(t, len(perr.Token) < 1100)
+}
+
+func TestCheckDigest(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	digest := sha256.Sum256([]byte("I want a sandwich"))
+	assert.NoError(t, c.CheckDigest("SHA256", digest[:]))
+	assert.EqualValues(t, 0, c.BytesWritten())
+	wrong := sha256.Sum256([]byte("I want a burrito"))
+	err = c.CheckDigest("sha256", wrong[:])
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	err = c.CheckDigest("sha256", digest[:16])
+	assert.True(t, errors.Is(err, ErrWrongLength))
+	assert.Error(t, c.CheckDigest("sha512", digest[:]))
+}
+
+func TestLenient(t *testing.T) {
+	c, err := NewCheckerLenient(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha999-whatever`)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestLenientStillRejectsInvalidEntries(t *testing.T) {
+	// Unknown algorithms are skipped, but known ones must still be valid.
+	_, err := NewCheckerLenient(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha256-wibble`)
+	assert.True(t, errors.Is(err, ErrWrongLength))
+	_, err = NewCheckerLenient(`sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= wibble`)
+	assert.True(t, errors.Is(err, ErrMalformedEntry))
+}
+
+func TestLenientNoKnownAlgorithms(t *testing.T) {
+	_, err := NewCheckerLenient(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha999-whatever`)
+	var perr *ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+	assert.Equal(t, 0, perr.Index)
+}
+
+func TestLenientAdd(t *testing.T) {
+	c, err := NewCheckerLenient(`sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=`)
+	assert.NoError(t, err)
+	assert.NoError(t, c.Add(`sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j`))
+	assert.Equal(t, []string{"sha384", "sha256"}, c.Algorithms())
+}
+
+func TestVerifiedWith(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	assert.Equal(t, "", c.VerifiedWith())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, "sha384", c.VerifiedWith())
+	c.Write([]byte("!"))
+	assert.Error(t, c.Check())
+	assert.Equal(t, "", c.VerifiedWith())
+	c.Reset()
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	c.Reset()
+	assert.Equal(t, "", c.VerifiedWith())
+}
+
+func TestVerifiedWithStrongestOnly(t *testing.T) {
+	// The sha512 value is right, the sha384 one is wrong.
+	c, err := NewCheckerStrongest("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3k sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, "sha512", c.VerifiedWith())
+}
+
+func TestCheckMinStrength(t *testing.T) {
+	c, err := NewCheckerWithSHA1("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.CheckMinStrength("sha256"))
+	assert.NoError(t, c.CheckMinStrength("SHA1"))
+	err = c.CheckMinStrength("sha384")
+	assert.Error(t, err)
+	var ierr *IntegrityError
+	assert.False(t, errors.As(err, &ierr))
+}
+
+func TestCheckMinStrengthUnranked(t *testing.T) {
+	c, err := NewCheckerWithSHA1("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.CheckMinStrength("sha-384"))
+	assert.Error(t, c.CheckMinStrength("sha384"))
+	assert.NoError(t, c.CheckMinStrength("sha1"))
+}
+
+func TestCheckMinStrengthMismatch(t *testing.T) {
+	c, err := NewChecker("sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a burrito"))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(c.CheckMinStrength("sha256"), &ierr))
+}
+
+func TestCheckMinStrengthPriority(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", defaultHashes(), WithPriority("sha256", "sha512"))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.CheckMinStrength("sha512"))
+}
+
+func TestAnyMatch(t *testing.T) {
+	// The sha512 value is wrong but the sha256 one is right.
+	c, err := NewCheckerAnyMatch("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	result, err := c.CheckDetailed()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Algorithms))
+	assert.False(t, result.Algorithms[0].Matched)
+	assert.True(t, result.Algorithms[1].Matched)
+	assert.Equal(t, []string{"sha256"}, c.Matched())
+	assert.Equal(t, "sha256", c.VerifiedWith())
+	// Neither a strongest-only nor a default Checker would accept this.
+	c, err = NewCheckerStrongest(c.String())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+}
+
+func TestAnyMatchNoneMatch(t *testing.T) {
+	c, err := NewCheckerAnyMatch("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	err = c.Check()
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Equal(t, 2, len(ierr.Failures))
+	assert.Equal(t, "", c.VerifiedWith())
+}
+
+func TestAlsoCompute(t *testing.T) {
+	c, err := NewCheckerForHashes("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", defaultHashes(), AlsoCompute("SHA256", "sha512"))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	assert.Equal(t, []string{"sha384"}, c.Algorithms())
+	assert.Equal(t, []string{"sha384"}, c.Matched())
+	s, err := c.SumBase64("sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", s)
+	s, err = c.SumBase64("sha512")
+	assert.NoError(t, err)
+	assert.Equal(t, "xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", s)
+	// They should survive a reset too.
+	c.Reset()
+	c.Write([]byte("I want a sandwich"))
+	s, err = c.SumBase64("sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", s)
+}
+
+func TestAlsoComputeUnknown(t *testing.T) {
+	_, err := NewCheckerForHashes("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", defaultHashes(), AlsoCompute("md5"))
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
+
+func TestWriteOnlyContract(t *testing.T) {
+	var w io.Writer
+	w, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	_, ok := w.(io.WriterAt)
+	assert.False(t, ok, "Checker must not implement io.WriterAt")
+	_, ok = w.(io.Seeker)
+	assert.False(t, ok, "Checker must not implement io.Seeker")
+	_, ok = w.(io.StringWriter)
+	assert.True(t, ok)
+	_, ok = w.(io.ReaderFrom)
+	assert.True(t, ok)
+}
+
+func TestReadFrom(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	n, err := c.ReadFrom(iotest.OneByteReader(strings.NewReader("I want a sandwich")))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 17, n)
+	assert.EqualValues(t, 17, c.BytesWritten())
+	assert.NoError(t, c.Check())
+}
+
+func TestReadFromError(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	_, err = c.ReadFrom(iotest.TimeoutReader(strings.NewReader("I want a sandwich")))
+	assert.Equal(t, iotest.ErrTimeout, err)
+}
+
+func TestReadFromLimit(t *testing.T) {
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 10)
+	assert.NoError(t, err)
+	n, err := c.ReadFrom(strings.NewReader("I want a sandwich"))
+	assert.Equal(t, ErrLimitExceeded, err)
+	assert.EqualValues(t, 10, n)
+}
+
+// onlyReader hides any other interfaces (e.g. io.WriterTo) that a reader implements.
+type onlyReader struct {
+	io.Reader
+}
+
+// onlyWriter hides any other interfaces (e.g. io.ReaderFrom) that a writer implements.
+type onlyWriter struct {
+	io.Writer
+}
+
+func BenchmarkCopyWithoutReadFrom(b *testing.B) {
+	c, _ := NewChecker(benchmarkSRI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		io.Copy(onlyWriter{c}, onlyReader{strings.NewReader(benchmarkString)})
+	}
+}
+
+func BenchmarkCopyWithReadFrom(b *testing.B) {
+	c, _ := NewChecker(benchmarkSRI)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		io.Copy(c, onlyReader{strings.NewReader(benchmarkString)})
+	}
+}
+
+func TestDefaultAlgorithms(t *testing.T) {
+	assert.Equal(t, []string{"sha512", "sha384", "sha256"}, DefaultAlgorithms())
+}
+
+func TestSupportedAlgorithms(t *testing.T) {
+	c, err := NewCheckerWithSHA1("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha384", "sha256", "sha1"}, c.SupportedAlgorithms())
+	assert.Equal(t, []string{"sha256"}, c.Algorithms())
+	c, err = NewCheckerForHashes("MD5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{"MD5": md5.New})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"md5"}, c.SupportedAlgorithms())
+}
+
+func TestWrongLengthBeforeDecoding(t *testing.T) {
+	c := newChecker(defaultHashes())
+	// This is far too long to be a sha256 digest so should be rejected on its length alone.
+	_, err := c.validateHash(sha256.Size, "sha256", strings.Repeat("A", 1024*1024))
+	assert.True(t, errors.Is(err, ErrWrongLength))
+	assert.Contains(t, err.Error(), "expected 44 base64 characters (32 bytes), was 1048576 characters (786432 bytes)")
+	// Invalid characters are still reported as such, regardless of length.
+	_, err = c.validateHash(sha256.Size, "sha256", "wibble!")
+	assert.True(t, errors.Is(err, ErrInvalidEncoding))
+	// Unpadded values are fine as long as they're the right length.
+	_, err = c.validateHash(sha256.Size, "sha256", "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0")
+	assert.NoError(t, err)
+}
+
+func TestUsesRecommendedAlgorithmsOnly(t *testing.T) {
+	c, err := NewCheckerWithSHA1("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	assert.True(t, c.UsesRecommendedAlgorithmsOnly())
+	assert.NoError(t, c.Add("sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU="))
+	assert.False(t, c.UsesRecommendedAlgorithmsOnly())
+	c, err = NewCheckerWithSHA3("sha3-256-m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8=")
+	assert.NoError(t, err)
+	assert.False(t, c.UsesRecommendedAlgorithmsOnly())
+	c, err = NewCheckerForHashes("md5-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{"md5": md5.New})
+	assert.NoError(t, err)
+	assert.False(t, c.UsesRecommendedAlgorithmsOnly())
+}
+
+func TestUnicodeSeparators(t *testing.T) {
+	for _, sep := range []string{"\r\n", "\t", "\u00a0", "\u2003", "\u3000", "\u200b", "\ufeff", " \u00a0\r\n\t"} {
+		c, err := NewChecker(sep + "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=" + sep + "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j" + sep)
+		if assert.NoError(t, err, "%q", sep) {
+			assert.Equal(t, []string{"sha384", "sha256"}, c.Algorithms())
+		}
+	}
+}
+
+func TestNewCheckerFromPairs(t *testing.T) {
+	c, err := NewCheckerFromPairs([]Pair{
+		{Algorithm: "SHA256", Digest: "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+		{Algorithm: "sha512", Digest: "xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="},
+	}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestNewCheckerFromPairsCustomHashes(t *testing.T) {
+	c, err := NewCheckerFromPairs([]Pair{{Algorithm: "md5", Digest: "IdZNPlbFer1sm3bEsO3Mpw=="}}, map[string]HashFunc{"md5": md5.New}, StrongestOnly())
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestNewCheckerFromPairsLenient(t *testing.T) {
+	c, err := NewCheckerFromPairs([]Pair{
+		{Algorithm: "md5", Digest: "IdZNPlbFer1sm3bEsO3Mpw=="},
+		{Algorithm: "sha256", Digest: "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+	}, nil, SkipUnknown())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	// It still fails if none of the algorithms are known.
+	_, err = NewCheckerFromPairs([]Pair{{Algorithm: "md5", Digest: "IdZNPlbFer1sm3bEsO3Mpw=="}}, nil, SkipUnknown())
+	var perr *ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+	assert.Equal(t, 0, perr.Index)
+}
+
+func TestNewCheckerFromPairsInvalid(t *testing.T) {
+	_, err := NewCheckerFromPairs([]Pair{
+		{Algorithm: "sha256", Digest: "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+		{Algorithm: "sha256", Digest: "wibble"},
+	}, nil)
+	var perr *ParseError
+	assert.True(t, errors.As(err, &perr))
+	assert.Equal(t, 1, perr.Index)
+	_, err = NewCheckerFromPairs([]Pair{{Algorithm: "md5", Digest: "IdZNPlbFer1sm3bEsO3Mpw=="}}, nil)
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+	_, err = NewCheckerFromPairs(nil, nil)
+	assert.Equal(t, ErrEmpty, err)
+	_, err = NewCheckerFromPairs([]Pair{{Algorithm: "sha256", Digest: "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}}, nil, RequireStrength("sha384"))
+	assert.Error(t, err)
+}
+
+func TestCheckAgainst(t *testing.T) {
+	c, err := NewChecker("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	// The SRI string doesn't match, but the allowlist does.
+	assert.Error(t, c.Check())
+	assert.NoError(t, c.CheckAgainst(map[string][]string{
+		"SHA256": {"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+		// This isn't being computed so is ignored.
+		"sha512": {"jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA=="},
+	}))
+	err = c.CheckAgainst(map[string][]string{"sha256": {"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="}})
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Equal(t, "sha256", ierr.Failures[0].Algorithm)
+}
+
+func TestCheckAgainstErrors(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	err = c.CheckAgainst(map[string][]string{"sha512": {"xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="}})
+	assert.Error(t, err)
+	var ierr *IntegrityError
+	assert.False(t, errors.As(err, &ierr))
+	err = c.CheckAgainst(map[string][]string{"sha256": {"wibble"}})
+	assert.True(t, errors.Is(err, ErrWrongLength))
+}
+
+func TestNewCheckerOptional(t *testing.T) {
+	for _, sri := range []string{"", " \n "} {
+		c, err := NewCheckerOptional(sri)
+		assert.NoError(t, err)
+		n, err := c.Write([]byte("I want a sandwich"))
+		assert.NoError(t, err)
+		assert.Equal(t, 17, n)
+		assert.EqualValues(t, 17, c.BytesWritten())
+		assert.NoError(t, c.Check())
+		assert.Equal(t, 0, len(c.Algorithms()))
+		assert.Equal(t, "", c.String())
+		assert.Equal(t, "", c.VerifiedWith())
+	}
+}
+
+func TestNewCheckerOptionalNonEmpty(t *testing.T) {
+	c, err := NewCheckerOptional("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a burrito"))
+	assert.Error(t, c.Check())
+	_, err = NewCheckerOptional("wibble")
+	assert.Error(t, err)
+}
+
+func TestWithEncoding(t *testing.T) {
+	c, err := NewCheckerForHashes("sha384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", defaultHashes(), WithEncoding(base64.URLEncoding))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"}, c.Expected("sha384"))
+	expected, ok := c.ExpectedOK("sha384")
+	assert.True(t, ok)
+	assert.Equal(t, c.Expected("sha384"), expected)
+	// String remains canonical.
+	assert.Equal(t, "sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", c.String())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+}
+
+func TestWithEncodingIsStrict(t *testing.T) {
+	for _, sri := range []string{
+		// Standard base64 isn't accepted since it contains + and /.
+		"sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j",
+		// Nor is hex.
+		"sha256-cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d",
+	} {
+		_, err := NewCheckerForHashes(sri, defaultHashes(), WithEncoding(base64.URLEncoding))
+		assert.Error(t, err, sri)
+	}
+	// Padding is required for a padded encoding.
+	_, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0", defaultHashes(), WithEncoding(base64.StdEncoding))
+	assert.True(t, errors.Is(err, ErrWrongLength))
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0", defaultHashes(), WithEncoding(base64.RawStdEncoding))
+	assert.NoError(t, err)
+	// Add is equally strict.
+	assert.Error(t, c.Add("sha384-4QuseiT9WQ-80EDZ_MYTodasdNBTLIC_9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"))
+}
+
+func TestWeakest(t *testing.T) {
+	c, err := NewCheckerWithSHA1("sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw== sha1-plyJ8jPttaMEVHl2WQbzDVT4pfU= sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	assert.Equal(t, "sha1", c.Weakest())
+	c, err = NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==", defaultHashes(), WithPriority("sha256", "sha512"))
+	assert.NoError(t, err)
+	assert.Equal(t, "sha512", c.Weakest())
+	c, err = NewCheckerOptional("")
+	assert.NoError(t, err)
+	assert.Equal(t, "", c.Weakest())
+}
+
+func TestOnResult(t *testing.T) {
+	type result struct {
+		algorithm string
+		matched   bool
+		computed  string
+	}
+	var results []result
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-jt9sSgTPOFnKQWLknlJEWjBq6UaOcjZzJOwlSgaEWr1b8IfmBmOMJZ91TmrZzjbUUB211oxxKEjyOBQHeXiDoA==", defaultHashes(), OnResult(func(algorithm string, matched bool, computed string) {
+		results = append(results, result{algorithm: algorithm, matched: matched, computed: computed})
+	}))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.Error(t, c.Check())
+	assert.Equal(t, []result{
+		{algorithm: "sha512", matched: false, computed: "xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="},
+		{algorithm: "sha256", matched: true, computed: "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="},
+	}, results)
+}
+
+func TestSHADashPrefix(t *testing.T) {
+	c, err := NewChecker("SHA-256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha-512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha256"}, c.Algorithms())
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	c, err = NewCheckerWithSHA1("Sha-1-plyJ8jPttaMEVHl2WQbzDVT4pfU=")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha1"}, c.Algorithms())
+}
+
+func TestSHADashPrefixInvalid(t *testing.T) {
+	for _, sri := range []string{
+		"sha-256",
+		"sha-256-",
+		"sha--y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+		"sha-25x-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=",
+	} {
+		_, err := NewChecker(sri)
+		assert.Error(t, err, sri)
+	}
+}
+
+func TestSHADashPrefixExactName(t *testing.T) {
+	// If a hash really is called sha-256, that takes precedence.
+	c, err := NewCheckerForHashes("sha-256-IdZNPlbFer1sm3bEsO3Mpw==", map[string]HashFunc{"sha-256": md5.New})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha-256"}, c.Algorithms())
+}
+
+func TestClose(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.WriteString("I want a sandwich")
+	assert.NoError(t, c.Check())
+	assert.NoError(t, c.Close())
+	assert.NoError(t, c.Close())
+	_, err = c.Write([]byte("I want a sandwich"))
+	assert.Equal(t, ErrClosed, err)
+	_, err = c.WriteString("I want a sandwich")
+	assert.Equal(t, ErrClosed, err)
+	_, err = c.ReadFrom(strings.NewReader("I want a sandwich"))
+	assert.Equal(t, ErrClosed, err)
+	assert.Equal(t, ErrClosed, c.Check())
+	assert.Equal(t, ErrClosed, c.CheckAlgorithm("sha256"))
+	c.Reset()
+	assert.Equal(t, ErrClosed, c.Check())
+	// Describing it still works though.
+	assert.Equal(t, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", c.String())
+}
+
+func TestIsExpected(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	assert.True(t, c.IsExpected("sha256", "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="))
+	assert.True(t, c.IsExpected("SHA256", "49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI"))
+	assert.True(t, c.IsExpected("sha256", "cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d"))
+	assert.False(t, c.IsExpected("sha256", "m3JbNOesjictcNlRjrpmlTr2CUm7/VgQ2R8IoQzTaG8="))
+	assert.False(t, c.IsExpected("sha256", "wibble"))
+	assert.False(t, c.IsExpected("sha512", "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="))
+	assert.False(t, c.IsExpected("md5", "IdZNPlbFer1sm3bEsO3Mpw=="))
+}
+
+func TestEqual(t *testing.T) {
+	a, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	b, err := NewCheckerAnyMatch("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j sha256-e3d870012a86bf0defe68ab63eee14da34763eff4a08c9b6546140a82c045e12 sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0")
+	assert.NoError(t, err)
+	assert.True(t, a.Equal(b))
+	assert.True(t, b.Equal(a))
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j")
+	assert.NoError(t, err)
+	assert.False(t, a.Equal(c))
+	assert.False(t, c.Equal(a))
+	assert.False(t, a.Equal(nil))
+}
+
+func TestStrongestOnlyComputesStrongest(t *testing.T) {
+	c, err := NewCheckerStrongest("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sha512", "sha256"}, c.Algorithms())
+	assert.Equal(t, []string{"49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI="}, c.Expected("sha256"))
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	_, err = c.Sum("sha512")
+	assert.NoError(t, err)
+	_, err = c.Sum("sha256")
+	assert.Error(t, err)
+	assert.Error(t, c.CheckAlgorithm("sha256"))
+	assert.NoError(t, c.CheckAlgorithm("sha512"))
+}
+
+func TestStrongestOnlyAlsoCompute(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j", defaultHashes(), StrongestOnly(), AlsoCompute("sha256"))
+	assert.NoError(t, err)
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	sum, err := c.SumBase64("sha256")
+	assert.NoError(t, err)
+	assert.Equal(t, "y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", sum)
+}
+
+func TestStrongestOnlyAdd(t *testing.T) {
+	c, err := NewCheckerStrongest("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=")
+	assert.NoError(t, err)
+	assert.NoError(t, c.Add("sha384-4QuseiT9WQ+80EDZ/MYTodasdNBTLIC/9G1XmSQDmTjTvDM8q00Vgxa9nMgwUw3j"))
+	c.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c.Check())
+	_, err = c.Sum("sha256")
+	assert.Error(t, err)
+	c2, err := c.Clone()
+	assert.NoError(t, err)
+	assert.NoError(t, c2.Check())
+}
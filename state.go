@@ -0,0 +1,102 @@
+package sri
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"hash"
+)
+
+// WriteChunks writes each of the given chunks to the Checker in order, as if they were one
+// contiguous piece of content. It returns the total number of bytes written.
+func (c *Checker) WriteChunks(chunks ...[]byte) (int64, error) {
+	var total int64
+	for _, chunk := range chunks {
+		n, err := c.Write(chunk)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// checkerState is the serialised form of a Checker's state, as produced by MarshalState.
+type checkerState struct {
+	Written  int64             `json:"written"`
+	Exceeded bool              `json:"exceeded,omitempty"`
+	Hashes   map[string][]byte `json:"hashes"`
+}
+
+// MarshalState returns a serialised form of the state of the data written to this Checker so far,
+// which can later be passed to RestoreState to continue verification (e.g. to resume a download after
+// a restart). It doesn't include the expected values, which should be supplied again when creating
+// the Checker to restore into.
+//
+// It returns an error if any of the underlying hashes don't implement encoding.BinaryMarshaler
+// (all the standard library ones do).
+func (c *Checker) MarshalState() ([]byte, error) {
+	state := checkerState{
+		Written:  c.written,
+		Exceeded: c.exceeded,
+		Hashes:   make(map[string][]byte, len(c.hashes)),
+	}
+	for name, h := range c.hashes {
+		b, err := marshalHash(name, h)
+		if err != nil {
+			return nil, err
+		}
+		state.Hashes[name] = b
+	}
+	return json.Marshal(state)
+}
+
+// RestoreState restores state previously produced by MarshalState, after which the Checker is as if
+// the same data had been written to it. It must be computing the same set of algorithms as the
+// Checker the state came from (typically because it was created from the same SRI string).
+// If it returns an error, the Checker is unchanged.
+func (c *Checker) RestoreState(data []byte) error {
+	var state checkerState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return fmt.Errorf("Invalid checker state: %w", err)
+	} else if len(state.Hashes) != len(c.funcs) {
+		return fmt.Errorf("Checker state contains %d hashes, expected %d", len(state.Hashes), len(c.funcs))
+	}
+	hashes := make(map[string]hash.Hash, len(c.funcs))
+	for name, f := range c.funcs {
+		b, present := state.Hashes[name]
+		if !present {
+			return fmt.Errorf("Checker state does not contain hash type %s", name)
+		}
+		h := f()
+		if err := unmarshalHash(name, h, b); err != nil {
+			return err
+		}
+		hashes[name] = h
+	}
+	c.hashes = hashes
+	c.written = state.Written
+	c.exceeded = state.Exceeded
+	c.matched = nil
+	c.verified = ""
+	c.updateWriter()
+	return nil
+}
+
+// marshalHash returns the internal state of the given hash.
+func marshalHash(name string, h hash.Hash) ([]byte, error) {
+	marshaler, ok := h.(encoding.BinaryMarshaler)
+	if !ok {
+		return nil, fmt.Errorf("Hash type %s does not support marshalling its state", name)
+	}
+	return marshaler.MarshalBinary()
+}
+
+// unmarshalHash restores the internal state of the given hash.
+func unmarshalHash(name string, h hash.Hash, state []byte) error {
+	unmarshaler, ok := h.(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("Hash type %s does not support unmarshalling its state", name)
+	}
+	return unmarshaler.UnmarshalBinary(state)
+}
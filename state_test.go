@@ -0,0 +1,67 @@
+package sri
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteChunks(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	n, err := c.WriteChunks([]byte("I want"), nil, []byte(" a "), []byte("sandwich"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 17, n)
+	assert.NoError(t, c.Check())
+}
+
+func TestWriteChunksLimit(t *testing.T) {
+	c, err := NewCheckerWithLimit("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", 10)
+	assert.NoError(t, err)
+	n, err := c.WriteChunks([]byte("I want"), []byte(" a "), []byte("sandwich"))
+	assert.Equal(t, ErrLimitExceeded, err)
+	assert.EqualValues(t, 10, n)
+}
+
+func TestMarshalState(t *testing.T) {
+	const sri = "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw=="
+	c, err := NewChecker(sri)
+	assert.NoError(t, err)
+	c.Write([]byte("I want a "))
+	state, err := c.MarshalState()
+	assert.NoError(t, err)
+
+	// Simulate restarting with a new Checker.
+	c2, err := NewChecker(sri)
+	assert.NoError(t, err)
+	assert.NoError(t, c2.RestoreState(state))
+	assert.EqualValues(t, 9, c2.BytesWritten())
+	c2.Write([]byte("sandwich"))
+	assert.NoError(t, c2.Check())
+	assert.EqualValues(t, 17, c2.BytesWritten())
+}
+
+func TestRestoreStateMismatch(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=")
+	assert.NoError(t, err)
+	c.Write([]byte("I want a "))
+	state, err := c.MarshalState()
+	assert.NoError(t, err)
+
+	c2, err := NewChecker("sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	assert.Error(t, c2.RestoreState(state))
+	assert.Error(t, c2.RestoreState([]byte("wibble")))
+	// It should be unchanged after the failures.
+	c2.Write([]byte("I want a sandwich"))
+	assert.NoError(t, c2.Check())
+}
+
+func TestMarshalStateUnsupported(t *testing.T) {
+	c, err := NewCheckerForHashes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", map[string]HashFunc{
+		"sha256": newUnmarshalableSHA256,
+	})
+	assert.NoError(t, err)
+	_, err = c.MarshalState()
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package sri
+
+import "sync"
+
+// A SyncChecker wraps a Checker with a mutex so that it can safely be shared between goroutines.
+//
+// Note that while concurrent writes are safe, the content is hashed in whatever order the writes
+// happen to occur, so producers need to coordinate between themselves if the order matters
+// (which it generally does).
+type SyncChecker struct {
+	mutex sync.Mutex
+	c     *Checker
+}
+
+// NewSyncChecker returns a new SyncChecker wrapping the given Checker.
+// The Checker should not be used directly afterwards.
+func NewSyncChecker(c *Checker) *SyncChecker {
+	return &SyncChecker{c: c}
+}
+
+// Write implements the io.Writer interface.
+func (s *SyncChecker) Write(b []byte) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.Write(b)
+}
+
+// Check is like Checker.Check.
+func (s *SyncChecker) Check() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.Check()
+}
+
+// CheckDetailed is like Checker.CheckDetailed.
+func (s *SyncChecker) CheckDetailed() (*Result, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.CheckDetailed()
+}
+
+// Reset is like Checker.Reset.
+func (s *SyncChecker) Reset() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.c.Reset()
+}
+
+// BytesWritten is like Checker.BytesWritten.
+func (s *SyncChecker) BytesWritten() int64 {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.BytesWritten()
+}
+
+// Expected is like Checker.Expected.
+func (s *SyncChecker) Expected(name string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.Expected(name)
+}
+
+// ExpectedHex is like Checker.ExpectedHex.
+func (s *SyncChecker) ExpectedHex(name string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.ExpectedHex(name)
+}
+
+// Algorithms is like Checker.Algorithms.
+func (s *SyncChecker) Algorithms() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.c.Algorithms()
+}
@@ -0,0 +1,55 @@
+package sri
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncChecker(t *testing.T) {
+	// We write the same byte many times so the order doesn't matter.
+	expected, err := Generate([]byte(strings.Repeat("a", 1000)), "sha256", "sha512")
+	assert.NoError(t, err)
+	c, err := NewChecker(expected)
+	assert.NoError(t, err)
+	s := NewSyncChecker(c)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Write([]byte("a"))
+				s.Expected("sha256")
+				s.Algorithms()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1000, s.BytesWritten())
+	assert.NoError(t, s.Check())
+	result, err := s.CheckDetailed()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(result.Algorithms))
+	assert.Equal(t, c.ExpectedHex("sha512"), s.ExpectedHex("sha512"))
+	s.Reset()
+	assert.Error(t, s.Check())
+}
+
+func TestConcurrentAccessors(t *testing.T) {
+	c, err := NewChecker("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0= sha512-xLpYEEen45RJnXxmFACS66+sO/1Xuo192Xq6uIarYI4uE7MZevI2pTyoKUZAFVP9tvfhJTS6YjOJcMc8ckoRkw==")
+	assert.NoError(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Equal(t, []string{"cb5bf7d4d92d2eb28b569d606d2ef38d6b5880320210d130ee128b247730e04d"}, c.ExpectedHex("sha256"))
+			assert.Equal(t, []string{"sha512", "sha256"}, c.Algorithms())
+			assert.Equal(t, []string{"y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="}, c.Expected("sha256"))
+		}()
+	}
+	wg.Wait()
+}
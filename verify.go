@@ -0,0 +1,122 @@
+package sri
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// VerifyBytes checks the given data against the given SRI string.
+// It supports the same set of algorithms as NewChecker.
+func VerifyBytes(sri string, data []byte) error {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return err
+	}
+	c.Write(data)
+	return c.Check()
+}
+
+// VerifyFile checks the contents of the file at the given path against the given SRI string.
+// It supports the same set of algorithms as NewChecker.
+func VerifyFile(sri, path string) error {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("Failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+	if err := c.Verify(f); err != nil {
+		return fmt.Errorf("Failed to verify %s: %w", path, err)
+	}
+	return nil
+}
+
+// VerifyReaderSized checks that exactly size bytes can be read from the given reader and that
+// they match the given SRI string. A short or long read returns an error wrapping ErrSizeMismatch
+// without the digests being compared; otherwise a mismatched digest returns an *IntegrityError.
+// At most size+1 bytes are read from r.
+func VerifyReaderSized(sri string, size int64, r io.Reader) error {
+	if size < 0 {
+		return fmt.Errorf("Invalid expected size %d", size)
+	}
+	c, err := NewChecker(sri)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(c, io.LimitReader(r, size+1))
+	if err != nil {
+		return err
+	} else if n < size {
+		return fmt.Errorf("%w: read %d bytes, expected %d", ErrSizeMismatch, n, size)
+	} else if n > size {
+		return fmt.Errorf("%w: read more than the expected %d bytes", ErrSizeMismatch, size)
+	}
+	return c.Check()
+}
+
+// VerifyCopy copies from src to dst while checking the content against the given SRI string,
+// returning the number of bytes copied. It supports the same set of algorithms as NewChecker.
+//
+// Since verification can only complete once everything has been read, the content has already been
+// written to dst by the time a mismatch is detected. In that case the returned error says so and wraps
+// an *IntegrityError; callers should discard whatever was written (e.g. by removing the file).
+func VerifyCopy(dst io.Writer, sri string, src io.Reader) (int64, error) {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(io.MultiWriter(dst, c), src)
+	if err != nil {
+		return n, err
+	} else if err := c.Check(); err != nil {
+		return n, fmt.Errorf("%d bytes were copied to the destination before verification failed and should be discarded: %w", n, err)
+	}
+	return n, nil
+}
+
+// VerifyReaderAt checks the first size bytes of the given io.ReaderAt against the given SRI string,
+// reading them sequentially in bounded chunks. It is useful for verifying a region of a larger file
+// (use an io.SectionReader to verify one that doesn't start at the beginning).
+// If fewer than size bytes are available it returns an error wrapping ErrSizeMismatch.
+// It supports the same set of algorithms as NewChecker.
+func VerifyReaderAt(sri string, ra io.ReaderAt, size int64) error {
+	if size < 0 {
+		return fmt.Errorf("Invalid expected size %d", size)
+	}
+	c, err := NewChecker(sri)
+	if err != nil {
+		return err
+	}
+	n, err := c.ReadFrom(io.NewSectionReader(ra, 0, size))
+	if err != nil {
+		return err
+	} else if n < size {
+		return fmt.Errorf("%w: read %d bytes, expected %d", ErrSizeMismatch, n, size)
+	}
+	return c.Check()
+}
+
+// VerifyAndRead reads all of the given reader and returns its content only if it matches the given
+// SRI string; on failure it returns nil, so unverified content can't accidentally be used.
+// It supports the same set of algorithms as NewChecker.
+//
+// The content is buffered entirely in memory; to bound that, wrap r in an io.LimitReader
+// (or use VerifyCopy to stream it somewhere else instead).
+func VerifyAndRead(sri string, r io.Reader) ([]byte, error) {
+	c, err := NewChecker(sri)
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(io.TeeReader(r, c))
+	if err != nil {
+		return nil, err
+	} else if err := c.Check(); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
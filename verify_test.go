@@ -0,0 +1,136 @@
+package sri
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyFile(t *testing.T) {
+	path := writeTempFile(t, "I want a sandwich")
+	defer os.Remove(path)
+	assert.NoError(t, VerifyFile("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", path))
+	assert.Error(t, VerifyFile("sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", path))
+	assert.Error(t, VerifyFile("wibble", path))
+}
+
+func TestVerifyFileMissing(t *testing.T) {
+	err := VerifyFile("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", "/this/file/does/not/exist")
+	assert.Error(t, err)
+	assert.True(t, os.IsNotExist(errors.Unwrap(err)))
+}
+
+// writeTempFile writes the given contents to a new temporary file and returns its path.
+func writeTempFile(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "sri_test")
+	assert.NoError(t, err)
+	defer f.Close()
+	_, err = f.WriteString(contents)
+	assert.NoError(t, err)
+	return f.Name()
+}
+
+func TestVerifyBytes(t *testing.T) {
+	assert.NoError(t, VerifyBytes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", []byte("I want a sandwich")))
+	err := VerifyBytes("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", []byte("I want a burrito"))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	err = VerifyBytes("wibble", []byte("I want a sandwich"))
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &ierr))
+}
+
+func TestVerifyReaderSized(t *testing.T) {
+	const sri = "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0="
+	assert.NoError(t, VerifyReaderSized(sri, 17, strings.NewReader("I want a sandwich")))
+	// Short and long reads fail on size, not on the digest.
+	err := VerifyReaderSized(sri, 17, strings.NewReader("I want a sand"))
+	assert.True(t, errors.Is(err, ErrSizeMismatch))
+	err = VerifyReaderSized(sri, 17, strings.NewReader("I want a sandwich and a pickle"))
+	assert.True(t, errors.Is(err, ErrSizeMismatch))
+	// The right size but the wrong content fails on the digest.
+	err = VerifyReaderSized(sri, 17, strings.NewReader("I want a sandwhich"[:17]))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.False(t, errors.Is(err, ErrSizeMismatch))
+	assert.Error(t, VerifyReaderSized(sri, -1, strings.NewReader("")))
+}
+
+func TestVerifyCopy(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := VerifyCopy(&buf, "sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a sandwich"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 17, n)
+	assert.Equal(t, "I want a sandwich", buf.String())
+}
+
+func TestVerifyCopyMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := VerifyCopy(&buf, "sha256-49hwASqGvw3v5oq2Pu4U2jR2Pv9KCMm2VGFAqCwEXhI=", strings.NewReader("I want a sandwich"))
+	assert.EqualValues(t, 17, n)
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Contains(t, err.Error(), "should be discarded")
+	// It's still been written though.
+	assert.Equal(t, "I want a sandwich", buf.String())
+}
+
+func TestVerifyCopyInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	n, err := VerifyCopy(&buf, "wibble", strings.NewReader("I want a sandwich"))
+	assert.Error(t, err)
+	assert.EqualValues(t, 0, n)
+	assert.Equal(t, 0, buf.Len())
+}
+
+func TestVerifyReaderAt(t *testing.T) {
+	r := strings.NewReader("I want a sandwich and a pickle")
+	assert.NoError(t, VerifyReaderAt("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", r, 17))
+	err := VerifyReaderAt("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", r, 18)
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	// A region in the middle of a larger reader can be verified via a SectionReader.
+	r = strings.NewReader("Today I want a sandwich")
+	assert.NoError(t, VerifyReaderAt("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", io.NewSectionReader(r, 6, 17), 17))
+}
+
+func TestVerifyReaderAtNegativeSize(t *testing.T) {
+	err := VerifyReaderAt("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a sandwich"), -1)
+	assert.Error(t, err)
+	var ierr *IntegrityError
+	assert.False(t, errors.As(err, &ierr))
+}
+
+func TestVerifyReaderAtTooShort(t *testing.T) {
+	err := VerifyReaderAt("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a sandwich"), 100)
+	assert.True(t, errors.Is(err, ErrSizeMismatch))
+}
+
+func TestVerifyAndRead(t *testing.T) {
+	b, err := VerifyAndRead("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a sandwich"))
+	assert.NoError(t, err)
+	assert.Equal(t, "I want a sandwich", string(b))
+}
+
+func TestVerifyAndReadMismatch(t *testing.T) {
+	b, err := VerifyAndRead("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", strings.NewReader("I want a burrito"))
+	var ierr *IntegrityError
+	assert.True(t, errors.As(err, &ierr))
+	assert.Nil(t, b)
+}
+
+func TestVerifyAndReadErrors(t *testing.T) {
+	b, err := VerifyAndRead("sha256-y1v31NktLrKLVp1gbS7zjWtYgDICENEw7hKLJHcw4E0=", iotest.TimeoutReader(strings.NewReader("I want a sandwich")))
+	assert.Error(t, err)
+	assert.Nil(t, b)
+	b, err = VerifyAndRead("wibble", strings.NewReader("I want a sandwich"))
+	assert.Error(t, err)
+	assert.Nil(t, b)
+}